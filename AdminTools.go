@@ -7,12 +7,19 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Item struct {
-	Name     string `json:"name"`
-	Quantity int    `json:"quantity"`
-	Cost     int    `json:"cost"`
+	Name             string `json:"name"`
+	Quantity         int    `json:"quantity"`
+	Cost             int    `json:"cost"`
+	Category         string `json:"category"`
+	CostPrice        int    `json:"cost_price"`
+	BestBefore       string `json:"best_before"`
+	ReorderThreshold int    `json:"reorder_threshold"`
 }
 
 func (cfg *ApiConfig) HandlerInsertItem(w http.ResponseWriter, r *http.Request) {
@@ -54,13 +61,77 @@ func (cfg *ApiConfig) HandlerInsertItem(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	bestBefore, err := parseBestBefore(newItem.BestBefore)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newItem.ReorderThreshold < 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
 	args := database.InsertItemParams{
-		Name:     newItem.Name,
-		Quantity: int32(newItem.Quantity),
-		Cost:     int32(newItem.Cost),
+		Name:             newItem.Name,
+		Quantity:         int32(newItem.Quantity),
+		Cost:             int32(newItem.Cost),
+		Category:         newItem.Category,
+		ReorderThreshold: int32(newItem.ReorderThreshold),
+	}
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	item, err := qtx.InsertItem(context.Background(), args)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = setPrice(context.Background(), qtx, item.ID, item.Cost, uuid.NullUUID{UUID: adminID, Valid: true})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if item.Quantity > 0 {
+		batch, err := qtx.CreateItemBatch(context.Background(), database.CreateItemBatchParams{
+			ItemID:     item.ID,
+			ReceivedAt: time.Now(),
+			BestBefore: bestBefore,
+			CostPrice:  int32(newItem.CostPrice),
+			Quantity:   item.Quantity,
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		err = recordStockMovement(context.Background(), qtx, item.ID, item.Quantity, stockMovement{
+			Reason:    stockReasonRestock,
+			ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+			Reference: "batch:" + batch.ID.String(),
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
 	}
 
-	_, err = cfg.Queries.InsertItem(context.Background(), args)
+	err = tx.Commit()
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
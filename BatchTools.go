@@ -0,0 +1,282 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const defaultExpiringDays = 3
+
+type ItemBatch struct {
+	Quantity   int    `json:"quantity"`
+	CostPrice  int    `json:"cost_price"`
+	BestBefore string `json:"best_before"`
+}
+
+// parseBestBefore reads an optional best-before date, an empty string means the batch does not expire.
+func parseBestBefore(raw string) (sql.NullTime, error) {
+	if raw == "" {
+		return sql.NullTime{}, nil
+	}
+
+	bestBefore, err := time.Parse(dayLayout, raw)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	return sql.NullTime{Time: bestBefore, Valid: true}, nil
+}
+
+func (cfg *ApiConfig) HandlerReceiveBatch(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	newBatch := ItemBatch{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&newBatch)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	bestBefore, err := parseBestBefore(newBatch.BestBefore)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newBatch.Quantity <= 0 || newBatch.CostPrice < 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	item, err := qtx.GetItemById(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if item.IsBundle {
+		http.Error(w, `{"error": "Bundles are stocked through their components"}`, http.StatusBadRequest)
+		return
+	}
+
+	batch, err := qtx.CreateItemBatch(ctx, database.CreateItemBatchParams{
+		ItemID:     itemID,
+		ReceivedAt: time.Now(),
+		BestBefore: bestBefore,
+		CostPrice:  int32(newBatch.CostPrice),
+		Quantity:   int32(newBatch.Quantity),
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	_, err = qtx.AddItemStock(ctx, database.AddItemStockParams{
+		Amount: batch.Quantity,
+		ID:     itemID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = recordStockMovement(ctx, qtx, itemID, batch.Quantity, stockMovement{
+		Reason:    stockReasonRestock,
+		ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+		Reference: "batch:" + batch.ID.String(),
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if item.Quantity == 0 {
+		go cfg.notifyBackInStock(itemID, item.Name)
+	}
+
+	respData, err := json.Marshal(batch)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetExpiringBatches(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	days := defaultExpiringDays
+	if rawDays := r.URL.Query().Get("days"); rawDays != "" {
+		days, err = strconv.Atoi(rawDays)
+		if err != nil || days < 0 {
+			http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	batches, err := cfg.Queries.GetExpiringBatches(context.Background(), int32(days))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(batches)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) writeOffExpiredBatches(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := cfg.writeOffBatches(context.Background())
+		logger.Warn(err, "problem with writing off expired batches")
+	}
+}
+
+// writeOffBatches marks every batch past its best-before date as written off
+// and removes what was left of it from the item stock.
+func (cfg *ApiConfig) writeOffBatches(ctx context.Context) error {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	batches, err := qtx.WriteOffExpiredBatches(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if batch.Quantity == 0 {
+			continue
+		}
+
+		err = qtx.WriteOffItemStock(ctx, database.WriteOffItemStockParams{
+			Amount: batch.Quantity,
+			ID:     batch.ItemID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordStockMovement(ctx, qtx, batch.ItemID, -batch.Quantity, stockMovement{
+			Reason:    stockReasonWriteOff,
+			Reference: "batch:" + batch.ID.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
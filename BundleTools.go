@@ -0,0 +1,160 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type BundleComponent struct {
+	ItemID   uuid.UUID `json:"item_id"`
+	Quantity int       `json:"quantity"`
+}
+
+type Bundle struct {
+	Name       string            `json:"name"`
+	Cost       int               `json:"cost"`
+	Category   string            `json:"category"`
+	Components []BundleComponent `json:"components"`
+}
+
+type BundleWithComponents struct {
+	database.Item
+	Components []BundleComponent `json:"components"`
+}
+
+func (cfg *ApiConfig) HandlerCreateBundle(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	newBundle := Bundle{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&newBundle)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newBundle.Name == "" || newBundle.Cost < 0 || len(newBundle.Components) == 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+	for _, component := range newBundle.Components {
+		if component.Quantity <= 0 {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := context.Background()
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	bundle, err := qtx.CreateBundle(ctx, database.CreateBundleParams{
+		Name:     newBundle.Name,
+		Cost:     int32(newBundle.Cost),
+		Category: newBundle.Category,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = setPrice(ctx, qtx, bundle.ID, bundle.Cost, uuid.NullUUID{UUID: adminID, Valid: true})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	for _, component := range newBundle.Components {
+		item, err := qtx.GetItemById(ctx, component.ItemID)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+		if item.IsBundle {
+			http.Error(w, `{"error": "Bundles can not contain other bundles"}`, http.StatusBadRequest)
+			return
+		}
+
+		err = qtx.AddBundleComponent(ctx, database.AddBundleComponentParams{
+			BundleID: bundle.ID,
+			ItemID:   component.ItemID,
+			Quantity: int32(component.Quantity),
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(BundleWithComponents{
+		Item:       bundle,
+		Components: newBundle.Components,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
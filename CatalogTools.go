@@ -0,0 +1,467 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const maxCatalogImportSize = 10 << 20
+
+var catalogColumns = []string{"name", "quantity", "cost", "category", "reorder_threshold"}
+
+// CatalogRow is one item in an import or export. Quantity and reorder threshold are optional
+// on import: when they are missing an existing item keeps its current values.
+type CatalogRow struct {
+	Name             string `json:"name"`
+	Quantity         *int   `json:"quantity"`
+	Cost             *int   `json:"cost"`
+	Category         string `json:"category"`
+	ReorderThreshold *int   `json:"reorder_threshold"`
+}
+
+type ImportError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	DryRun  bool          `json:"dry_run"`
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Errors  []ImportError `json:"errors"`
+}
+
+// catalogFormat picks csv or json from the format query parameter, falling back to the content type.
+func catalogFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+// parseCatalogCSV reads catalog rows from a CSV file whose header names the columns.
+func parseCatalogCSV(body io.Reader) ([]CatalogRow, []ImportError, error) {
+	reader := csv.NewReader(body)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, errors.New("csv header has no name column")
+	}
+
+	rows := []CatalogRow{}
+	importErrors := []ImportError{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		number := func(column string) *int {
+			raw := value(column)
+			if raw == "" {
+				return nil
+			}
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				importErrors = append(importErrors, ImportError{
+					Row:   len(rows) + 1,
+					Error: fmt.Sprintf("%s is not a number", column),
+				})
+				return nil
+			}
+			return &n
+		}
+
+		rows = append(rows, CatalogRow{
+			Name:             value("name"),
+			Quantity:         number("quantity"),
+			Cost:             number("cost"),
+			Category:         value("category"),
+			ReorderThreshold: number("reorder_threshold"),
+		})
+	}
+
+	return rows, importErrors, nil
+}
+
+// validateCatalogRows checks every row on its own, rows are numbered from 1.
+func validateCatalogRows(rows []CatalogRow) []ImportError {
+	importErrors := []ImportError{}
+	seen := map[string]int{}
+
+	for i, row := range rows {
+		problem := ""
+		switch {
+		case row.Name == "":
+			problem = "name is required"
+		case seen[row.Name] > 0:
+			problem = fmt.Sprintf("name is already used in row %d", seen[row.Name])
+		case row.Cost == nil:
+			problem = "cost is required"
+		case *row.Cost < 0:
+			problem = "cost can not be negative"
+		case row.Quantity != nil && *row.Quantity < 0:
+			problem = "quantity can not be negative"
+		case row.ReorderThreshold != nil && *row.ReorderThreshold < 0:
+			problem = "reorder_threshold can not be negative"
+		}
+
+		if problem != "" {
+			importErrors = append(importErrors, ImportError{Row: i + 1, Error: problem})
+		}
+		if row.Name != "" && seen[row.Name] == 0 {
+			seen[row.Name] = i + 1
+		}
+	}
+
+	return importErrors
+}
+
+// importCatalog upserts rows by item name in a single transaction. Quantities are stock counts,
+// the difference to the current stock goes through the batches and the ledger.
+// Nothing is written when a row fails or on a dry run.
+func (cfg *ApiConfig) importCatalog(ctx context.Context, adminID uuid.UUID, rows []CatalogRow, dryRun bool) (ImportResult, error) {
+	result := ImportResult{
+		DryRun: dryRun,
+		Errors: []ImportError{},
+	}
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	movement := stockMovement{
+		Reason:    stockReasonImport,
+		ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+		Reference: "import",
+	}
+
+	restocked := []restockedItem{}
+	for i, row := range rows {
+		existing, err := qtx.GetItemByName(ctx, row.Name)
+		if errors.Is(err, sql.ErrNoRows) {
+			item, err := qtx.InsertItem(ctx, database.InsertItemParams{
+				Name:             row.Name,
+				Quantity:         int32(valueOr(row.Quantity, 0)),
+				Cost:             int32(*row.Cost),
+				Category:         row.Category,
+				ReorderThreshold: int32(valueOr(row.ReorderThreshold, 0)),
+			})
+			if err != nil {
+				return ImportResult{}, err
+			}
+
+			err = setPrice(ctx, qtx, item.ID, item.Cost, movement.ActorID)
+			if err != nil {
+				return ImportResult{}, err
+			}
+
+			if item.Quantity > 0 {
+				_, err = qtx.CreateItemBatch(ctx, database.CreateItemBatchParams{
+					ItemID:     item.ID,
+					ReceivedAt: time.Now(),
+					Quantity:   item.Quantity,
+				})
+				if err != nil {
+					return ImportResult{}, err
+				}
+
+				err = recordStockMovement(ctx, qtx, item.ID, item.Quantity, movement)
+				if err != nil {
+					return ImportResult{}, err
+				}
+			}
+
+			result.Created++
+			continue
+		}
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		err = qtx.UpdateItemDetails(ctx, database.UpdateItemDetailsParams{
+			ID:               existing.ID,
+			Category:         stringOr(row.Category, existing.Category),
+			ReorderThreshold: int32(valueOr(row.ReorderThreshold, int(existing.ReorderThreshold))),
+		})
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		currentPrice, err := qtx.GetCurrentPrice(ctx, existing.ID)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if currentPrice != int32(*row.Cost) {
+			err = setPrice(ctx, qtx, existing.ID, int32(*row.Cost), movement.ActorID)
+			if err != nil {
+				return ImportResult{}, err
+			}
+		}
+
+		if row.Quantity != nil && !existing.IsBundle {
+			delta := int32(*row.Quantity) - existing.Quantity
+			switch {
+			case delta > 0:
+				batch, err := newestBatch(ctx, qtx, existing.ID)
+				if err != nil {
+					return ImportResult{}, err
+				}
+
+				items, err := putBackBatches(ctx, qtx, []batchAllocation{{BatchID: batch.ID, Quantity: delta}}, &movement)
+				if err != nil {
+					return ImportResult{}, err
+				}
+				restocked = append(restocked, items...)
+			case delta < 0:
+				_, err = takeBatches(ctx, qtx, existing.ID, -delta)
+				if errors.Is(err, errOutOfStock) {
+					result.Errors = append(result.Errors, ImportError{
+						Row:   i + 1,
+						Error: "part of the stock has expired, quantity can not be lowered yet",
+					})
+					continue
+				}
+				if err != nil {
+					return ImportResult{}, err
+				}
+
+				err = recordStockMovement(ctx, qtx, existing.ID, delta, movement)
+				if err != nil {
+					return ImportResult{}, err
+				}
+			}
+		}
+
+		result.Updated++
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	for _, item := range restocked {
+		go cfg.notifyBackInStock(item.ID, item.Name)
+	}
+
+	return result, nil
+}
+
+func valueOr(value *int, fallback int) int {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+func stringOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func (cfg *ApiConfig) HandlerImportItems(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+	body := http.MaxBytesReader(w, r.Body, maxCatalogImportSize)
+
+	rows := []CatalogRow{}
+	importErrors := []ImportError{}
+	switch catalogFormat(r) {
+	case "csv":
+		rows, importErrors, err = parseCatalogCSV(body)
+	case "json":
+		err = json.NewDecoder(body).Decode(&rows)
+	default:
+		err = errors.New("unknown catalog format")
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	result := ImportResult{
+		DryRun: dryRun,
+		Errors: append(importErrors, validateCatalogRows(rows)...),
+	}
+
+	if len(result.Errors) == 0 {
+		result, err = cfg.importCatalog(context.Background(), adminID, rows, dryRun)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	respData, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerExportItems(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	format := catalogFormat(r)
+	if format != "csv" && format != "json" {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	items, err := cfg.Queries.GetCatalog(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="catalog.csv"`)
+
+		writer := csv.NewWriter(w)
+		writer.Write(catalogColumns)
+		for _, item := range items {
+			writer.Write([]string{
+				item.Name,
+				strconv.Itoa(int(item.Quantity)),
+				strconv.Itoa(int(item.Cost)),
+				item.Category,
+				strconv.Itoa(int(item.ReorderThreshold)),
+			})
+		}
+		writer.Flush()
+		logger.Warn(writer.Error(), "problem with writing catalog export")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="catalog.json"`)
+
+	w.Write([]byte("["))
+	for i, item := range items {
+		quantity, cost, reorderThreshold := int(item.Quantity), int(item.Cost), int(item.ReorderThreshold)
+
+		respData, err := json.Marshal(CatalogRow{
+			Name:             item.Name,
+			Quantity:         &quantity,
+			Cost:             &cost,
+			Category:         item.Category,
+			ReorderThreshold: &reorderThreshold,
+		})
+		if err != nil {
+			logger.Warn(err, "problem with writing catalog export")
+			return
+		}
+
+		if i > 0 {
+			w.Write([]byte(","))
+		}
+		w.Write(respData)
+	}
+	w.Write([]byte("]"))
+}
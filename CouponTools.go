@@ -0,0 +1,400 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/promotions"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Coupon struct {
+	Code         string    `json:"code"`
+	DiscountType string    `json:"discount_type"`
+	Value        int       `json:"value"`
+	MaxUses      *int      `json:"max_uses"`
+	PerUserLimit int       `json:"per_user_limit"`
+	MinBasket    int       `json:"min_basket"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type CouponCode struct {
+	Code string `json:"code"`
+}
+
+var errCouponLimit = errors.New("coupon usage limit reached")
+
+// redeemCoupon uses up the coupon the cart was priced with and records it on the payment. The coupon row
+// stays locked until the transaction ends, so concurrent payments can not go over max_uses or the per user limit.
+func redeemCoupon(ctx context.Context, qtx *database.Queries, paymentRow database.Payment, pricing promotions.Result) (database.Payment, error) {
+	if pricing.Coupon == "" {
+		return paymentRow, nil
+	}
+
+	userID := paymentRow.UserID
+	coupon, err := qtx.GetCartCoupon(ctx, userID)
+	if err != nil {
+		return database.Payment{}, err
+	}
+
+	_, err = qtx.IncrementCouponUsage(ctx, coupon.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Payment{}, errCouponLimit
+	}
+	if err != nil {
+		return database.Payment{}, err
+	}
+
+	redemptions, err := qtx.CountUserRedemptions(ctx, database.CountUserRedemptionsParams{
+		CouponID: coupon.ID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return database.Payment{}, err
+	}
+	if redemptions >= int64(coupon.PerUserLimit) {
+		return database.Payment{}, errCouponLimit
+	}
+
+	err = qtx.InsertCouponRedemption(ctx, database.InsertCouponRedemptionParams{
+		CouponID: coupon.ID,
+		UserID:   userID,
+	})
+	if err != nil {
+		return database.Payment{}, err
+	}
+
+	err = qtx.DeleteCartCoupon(ctx, userID)
+	if err != nil {
+		return database.Payment{}, err
+	}
+
+	return qtx.SetPaymentCoupon(ctx, database.SetPaymentCouponParams{
+		ID:       paymentRow.ID,
+		CouponID: uuid.NullUUID{UUID: coupon.ID, Valid: true},
+	})
+}
+
+// returnCoupon gives back the coupon use of a payment that did not go through.
+func returnCoupon(ctx context.Context, qtx *database.Queries, paymentRow database.Payment) error {
+	if !paymentRow.CouponID.Valid {
+		return nil
+	}
+
+	err := qtx.DecrementCouponUsage(ctx, paymentRow.CouponID.UUID)
+	if err != nil {
+		return err
+	}
+
+	return qtx.DeleteLatestCouponRedemption(ctx, database.DeleteLatestCouponRedemptionParams{
+		CouponID: paymentRow.CouponID.UUID,
+		UserID:   paymentRow.UserID,
+	})
+}
+
+func couponToPromotions(c database.Coupon) promotions.Coupon {
+	return promotions.Coupon{
+		ID:        c.ID,
+		Code:      c.Code,
+		Type:      c.DiscountType,
+		Value:     int(c.Value),
+		MinBasket: int(c.MinBasket),
+		ExpiresAt: c.ExpiresAt,
+	}
+}
+
+func (cfg *ApiConfig) HandlerApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	couponCode := CouponCode{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&couponCode)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	coupon, err := cfg.Queries.GetCouponByCode(context.Background(), couponCode.Code)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Coupon not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if coupon.MaxUses.Valid && coupon.TimesUsed >= coupon.MaxUses.Int32 {
+		http.Error(w, `{"error": "Coupon usage limit reached"}`, http.StatusBadRequest)
+		return
+	}
+
+	redemptions, err := cfg.Queries.CountUserRedemptions(context.Background(), database.CountUserRedemptionsParams{
+		CouponID: coupon.ID,
+		UserID:   userID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if redemptions >= int64(coupon.PerUserLimit) {
+		http.Error(w, `{"error": "Coupon usage limit reached"}`, http.StatusBadRequest)
+		return
+	}
+
+	pricing, err := cfg.priceCartPromotions(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	pricing, err = promotions.ApplyCoupon(pricing, couponToPromotions(coupon), time.Now())
+	if errors.Is(err, promotions.ErrCouponExpired) {
+		http.Error(w, `{"error": "Coupon is expired"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, promotions.ErrBasketTooSmall) {
+		http.Error(w, `{"error": "Basket is below coupon minimum"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.SetCartCoupon(context.Background(), database.SetCartCouponParams{
+		UserID:   userID,
+		CouponID: coupon.ID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(pricing)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerRemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteCartCoupon(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *ApiConfig) HandlerGetCoupons(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	coupons, err := cfg.Queries.GetAllCoupons(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(coupons)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreateCoupon(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	newCoupon := Coupon{PerUserLimit: 1}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newCoupon)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = promotions.ValidateCoupon(promotions.Coupon{
+		Code:  newCoupon.Code,
+		Type:  newCoupon.DiscountType,
+		Value: newCoupon.Value,
+	})
+	if err != nil || newCoupon.PerUserLimit <= 0 || (newCoupon.MaxUses != nil && *newCoupon.MaxUses <= 0) || !newCoupon.ExpiresAt.After(time.Now()) {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	args := database.CreateCouponParams{
+		Code:         newCoupon.Code,
+		DiscountType: newCoupon.DiscountType,
+		Value:        int32(newCoupon.Value),
+		PerUserLimit: int32(newCoupon.PerUserLimit),
+		MinBasket:    int32(newCoupon.MinBasket),
+		ExpiresAt:    newCoupon.ExpiresAt,
+	}
+	if newCoupon.MaxUses != nil {
+		args.MaxUses = sql.NullInt32{Int32: int32(*newCoupon.MaxUses), Valid: true}
+	}
+
+	createdCoupon, err := cfg.Queries.CreateCoupon(context.Background(), args)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(createdCoupon)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	couponID, err := uuid.Parse(r.PathValue("couponID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteCoupon(context.Background(), couponID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
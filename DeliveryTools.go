@@ -0,0 +1,549 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/delivery"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type Address struct {
+	Label     string   `json:"label"`
+	Street    string   `json:"street"`
+	City      string   `json:"city"`
+	Postcode  string   `json:"postcode"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+type DeliveryZone struct {
+	Name          string           `json:"name"`
+	Postcodes     []string         `json:"postcodes"`
+	Polygon       []delivery.Point `json:"polygon"`
+	Fee           int              `json:"fee"`
+	FreeThreshold int              `json:"free_threshold"`
+}
+
+type DeliveryQuote struct {
+	ZoneID        uuid.UUID `json:"zone_id"`
+	Zone          string    `json:"zone"`
+	Fee           int       `json:"fee"`
+	FreeThreshold int       `json:"free_threshold"`
+	BasketTotal   int       `json:"basket_total"`
+}
+
+func (z DeliveryZone) valid() bool {
+	return z.Name != "" && z.Fee >= 0 && z.FreeThreshold >= 0 && (len(z.Postcodes) > 0 || len(z.Polygon) >= 3)
+}
+
+func (z DeliveryZone) polygonJSON() (json.RawMessage, error) {
+	if z.Polygon == nil {
+		return json.RawMessage("[]"), nil
+	}
+
+	return json.Marshal(z.Polygon)
+}
+
+func zoneToDelivery(z database.DeliveryZone) (delivery.Zone, error) {
+	zone := delivery.Zone{
+		ID:            z.ID,
+		Name:          z.Name,
+		Postcodes:     z.Postcodes,
+		Fee:           int(z.Fee),
+		FreeThreshold: int(z.FreeThreshold.Int32),
+	}
+
+	err := json.Unmarshal(z.Polygon, &zone.Polygon)
+	if err != nil {
+		return delivery.Zone{}, err
+	}
+
+	return zone, nil
+}
+
+func addressToDelivery(a database.Address) delivery.Address {
+	addr := delivery.Address{Postcode: a.Postcode}
+	if a.Latitude.Valid && a.Longitude.Valid {
+		addr.Location = &delivery.Point{Lat: a.Latitude.Float64, Lng: a.Longitude.Float64}
+	}
+
+	return addr
+}
+
+// deliveryZoneFor finds the zone serving the address, delivery.ErrOutsideZones if there is none.
+func (cfg *ApiConfig) deliveryZoneFor(ctx context.Context, addr database.Address) (delivery.Zone, error) {
+	dbZones, err := cfg.Queries.GetAllDeliveryZones(ctx)
+	if err != nil {
+		return delivery.Zone{}, err
+	}
+
+	zones := make([]delivery.Zone, 0, len(dbZones))
+	for _, z := range dbZones {
+		zone, err := zoneToDelivery(z)
+		if err != nil {
+			return delivery.Zone{}, err
+		}
+		zones = append(zones, zone)
+	}
+
+	return delivery.MatchZone(zones, addressToDelivery(addr))
+}
+
+func (cfg *ApiConfig) HandlerGetAddresses(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	addresses, err := cfg.Queries.GetUserAddresses(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(addresses)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreateAddress(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	newAddress := Address{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newAddress)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newAddress.Street == "" || newAddress.City == "" || newAddress.Postcode == "" {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	args := database.CreateAddressParams{
+		UserID:   userID,
+		Label:    newAddress.Label,
+		Street:   newAddress.Street,
+		City:     newAddress.City,
+		Postcode: delivery.NormalizePostcode(newAddress.Postcode),
+	}
+	if newAddress.Latitude != nil && newAddress.Longitude != nil {
+		args.Latitude = sql.NullFloat64{Float64: *newAddress.Latitude, Valid: true}
+		args.Longitude = sql.NullFloat64{Float64: *newAddress.Longitude, Valid: true}
+	}
+
+	createdAddress, err := cfg.Queries.CreateAddress(context.Background(), args)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(createdAddress)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeleteAddress(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	addressID, err := uuid.Parse(r.PathValue("addressID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteAddress(context.Background(), database.DeleteAddressParams{
+		ID:     addressID,
+		UserID: userID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *ApiConfig) HandlerGetDeliveryQuote(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	addressID, err := uuid.Parse(r.URL.Query().Get("address_id"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	address, err := cfg.Queries.GetUserAddress(context.Background(), database.GetUserAddressParams{
+		ID:     addressID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Address not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	zone, err := cfg.deliveryZoneFor(context.Background(), address)
+	if errors.Is(err, delivery.ErrOutsideZones) {
+		http.Error(w, `{"error": "Address is outside of delivery zones"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	pricing, err := cfg.priceCart(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(DeliveryQuote{
+		ZoneID:        zone.ID,
+		Zone:          zone.Name,
+		Fee:           zone.FeeFor(pricing.Total),
+		FreeThreshold: zone.FreeThreshold,
+		BasketTotal:   pricing.Total,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetDeliveryZones(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	zones, err := cfg.Queries.GetAllDeliveryZones(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(zones)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreateDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	newZone := DeliveryZone{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newZone)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if !newZone.valid() {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	polygon, err := newZone.polygonJSON()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	postcodes := make([]string, 0, len(newZone.Postcodes))
+	for _, p := range newZone.Postcodes {
+		postcodes = append(postcodes, delivery.NormalizePostcode(p))
+	}
+
+	createdZone, err := cfg.Queries.CreateDeliveryZone(context.Background(), database.CreateDeliveryZoneParams{
+		Name:          newZone.Name,
+		Postcodes:     postcodes,
+		Polygon:       polygon,
+		Fee:           int32(newZone.Fee),
+		FreeThreshold: sql.NullInt32{Int32: int32(newZone.FreeThreshold), Valid: newZone.FreeThreshold > 0},
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(createdZone)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerUpdateDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	zoneID, err := uuid.Parse(r.PathValue("zoneID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	updatedZone := DeliveryZone{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&updatedZone)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if !updatedZone.valid() {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	polygon, err := updatedZone.polygonJSON()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	postcodes := make([]string, 0, len(updatedZone.Postcodes))
+	for _, p := range updatedZone.Postcodes {
+		postcodes = append(postcodes, delivery.NormalizePostcode(p))
+	}
+
+	zone, err := cfg.Queries.UpdateDeliveryZone(context.Background(), database.UpdateDeliveryZoneParams{
+		ID:            zoneID,
+		Name:          updatedZone.Name,
+		Postcodes:     postcodes,
+		Polygon:       polygon,
+		Fee:           int32(updatedZone.Fee),
+		FreeThreshold: sql.NullInt32{Int32: int32(updatedZone.FreeThreshold), Valid: updatedZone.FreeThreshold > 0},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Delivery zone not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(zone)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeleteDeliveryZone(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	zoneID, err := uuid.Parse(r.PathValue("zoneID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteDeliveryZone(context.Background(), zoneID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	creditReasonIssue    = "gift_card_issue"
+	creditReasonRedeem   = "gift_card_redeem"
+	creditReasonCheckout = "checkout"
+	creditReasonReversal = "payment_reversal"
+	creditReasonRefund   = "refund"
+)
+
+// codeAlphabet leaves out characters that are easy to mix up, like 0 and O or 1 and I.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+var errNotEnoughCredit = errors.New("not enough store credit")
+
+// creditMovement tells the credit ledger why a balance changed, who changed it and what it belongs to.
+type creditMovement struct {
+	Reason    string
+	ActorID   uuid.NullUUID
+	Reference string
+}
+
+type NewGiftCard struct {
+	Amount    int        `json:"amount"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type GiftCardCode struct {
+	Code string `json:"code"`
+}
+
+type StoreCredit struct {
+	Balance   int32                     `json:"balance"`
+	Movements []database.CreditMovement `json:"movements"`
+}
+
+// randomCode makes a random code of length characters from codeAlphabet.
+func randomCode(length int) (string, error) {
+	raw := make([]byte, length)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+
+	for i, b := range raw {
+		raw[i] = codeAlphabet[int(b)%len(codeAlphabet)]
+	}
+
+	return string(raw), nil
+}
+
+// newGiftCardCode makes a random code of four groups of four characters, XXXX-XXXX-XXXX-XXXX.
+func newGiftCardCode() (string, error) {
+	code, err := randomCode(16)
+	if err != nil {
+		return "", err
+	}
+
+	return normalizeGiftCardCode(code), nil
+}
+
+// normalizeGiftCardCode accepts a code typed in lower case, with spaces or without dashes.
+func normalizeGiftCardCode(raw string) string {
+	code := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(raw))
+	if len(code) != 16 {
+		return code
+	}
+
+	return code[0:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:16]
+}
+
+// addStoreCredit puts amount on the store credit account of a user and records it in the ledger.
+func addStoreCredit(ctx context.Context, qtx *database.Queries, userID uuid.UUID, amount int32, movement creditMovement) error {
+	_, err := qtx.AddStoreCredit(ctx, database.AddStoreCreditParams{
+		UserID:  userID,
+		Balance: amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return qtx.InsertCreditMovement(ctx, database.InsertCreditMovementParams{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		Delta:     amount,
+		Reason:    movement.Reason,
+		ActorID:   movement.ActorID,
+		Reference: movement.Reference,
+	})
+}
+
+// takeStoreCredit spends amount of the store credit of a user, failing with errNotEnoughCredit
+// instead of letting the balance go negative.
+func takeStoreCredit(ctx context.Context, qtx *database.Queries, userID uuid.UUID, amount int32, movement creditMovement) error {
+	taken, err := qtx.TakeStoreCredit(ctx, database.TakeStoreCreditParams{
+		Amount: amount,
+		UserID: userID,
+	})
+	if err != nil {
+		return err
+	}
+	if taken == 0 {
+		return errNotEnoughCredit
+	}
+
+	return qtx.InsertCreditMovement(ctx, database.InsertCreditMovementParams{
+		UserID:    uuid.NullUUID{UUID: userID, Valid: true},
+		Delta:     -amount,
+		Reason:    movement.Reason,
+		ActorID:   movement.ActorID,
+		Reference: movement.Reference,
+	})
+}
+
+// returnStoreCredit gives back the store credit spent on a payment that did not go through.
+func returnStoreCredit(ctx context.Context, qtx *database.Queries, paymentRow database.Payment) error {
+	if paymentRow.StoreCredit <= 0 {
+		return nil
+	}
+
+	return addStoreCredit(ctx, qtx, paymentRow.UserID, paymentRow.StoreCredit, creditMovement{
+		Reason:    creditReasonReversal,
+		Reference: "payment:" + paymentRow.ID.String(),
+	})
+}
+
+func (cfg *ApiConfig) HandlerIssueGiftCard(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	newGiftCard := NewGiftCard{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&newGiftCard)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newGiftCard.Amount <= 0 || (newGiftCard.ExpiresAt != nil && !newGiftCard.ExpiresAt.After(time.Now())) {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	expiresAt := sql.NullTime{}
+	if newGiftCard.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *newGiftCard.ExpiresAt, Valid: true}
+	}
+
+	code, err := newGiftCardCode()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with generating gift card code"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	ctx := context.Background()
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	giftCard, err := qtx.CreateGiftCard(ctx, database.CreateGiftCardParams{
+		Code:      code,
+		Amount:    int32(newGiftCard.Amount),
+		IssuedBy:  uuid.NullUUID{UUID: adminID, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = qtx.InsertCreditMovement(ctx, database.InsertCreditMovementParams{
+		GiftCardID: uuid.NullUUID{UUID: giftCard.ID, Valid: true},
+		Delta:      giftCard.Amount,
+		Reason:     creditReasonIssue,
+		ActorID:    uuid.NullUUID{UUID: adminID, Valid: true},
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(giftCard)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetGiftCards(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	giftCards, err := cfg.Queries.GetGiftCards(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(giftCards)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetCreditLedger(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	movements, err := cfg.Queries.GetCreditLedger(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(movements)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+// HandlerRedeemGiftCard moves the whole value of a gift card to the store credit of the user.
+func (cfg *ApiConfig) HandlerRedeemGiftCard(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	giftCardCode := GiftCardCode{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&giftCardCode)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	ctx := context.Background()
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	giftCard, err := qtx.RedeemGiftCard(ctx, database.RedeemGiftCardParams{
+		Code:       normalizeGiftCardCode(giftCardCode.Code),
+		RedeemedBy: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Gift card not found, expired or already redeemed"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	reference := "gift_card:" + giftCard.ID.String()
+
+	err = qtx.InsertCreditMovement(ctx, database.InsertCreditMovementParams{
+		GiftCardID: uuid.NullUUID{UUID: giftCard.ID, Valid: true},
+		Delta:      -giftCard.Amount,
+		Reason:     creditReasonRedeem,
+		ActorID:    uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = addStoreCredit(ctx, qtx, userID, giftCard.Amount, creditMovement{
+		Reason:    creditReasonRedeem,
+		ActorID:   uuid.NullUUID{UUID: userID, Valid: true},
+		Reference: reference,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	balance, err := qtx.GetStoreCreditBalance(ctx, userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(StoreCredit{
+		Balance:   balance,
+		Movements: []database.CreditMovement{},
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetStoreCredit(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	balance, err := cfg.Queries.GetStoreCreditBalance(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	movements, err := cfg.Queries.GetUserCreditMovements(context.Background(), uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if movements == nil {
+		movements = []database.CreditMovement{}
+	}
+
+	respData, err := json.Marshal(StoreCredit{
+		Balance:   balance,
+		Movements: movements,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
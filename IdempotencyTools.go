@@ -0,0 +1,246 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/images"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxIdempotentBodySize = 1 << 20
+
+// notIdempotent are routes whose responses carry tokens, those must never be stored.
+var notIdempotent = map[string]bool{
+	"/api/reg":     true,
+	"/api/login":   true,
+	"/api/refresh": true,
+}
+
+// replayedHeaders are the response headers stored with an idempotent response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// idempotentBodyLimit is the largest body the route accepts, so the middleware never rejects
+// a request its handler would take.
+func idempotentBodyLimit(r *http.Request) int64 {
+	if r.URL.Path == "/admin/items/import" {
+		return maxCatalogImportSize
+	}
+	if strings.HasPrefix(r.URL.Path, "/admin/item/") && strings.HasSuffix(r.URL.Path, "/images") {
+		return images.MaxUploadSize + 1<<20
+	}
+
+	return maxIdempotentBodySize
+}
+
+// responseRecorder passes the response through and keeps a copy of it for replaying.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
+
+// idempotencyScope keeps keys of different users apart. Requests without a valid token have no scope.
+func (cfg *ApiConfig) idempotencyScope(r *http.Request) (string, bool) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		return "", false
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		return "", false
+	}
+
+	return userID.String(), true
+}
+
+// Idempotent makes POST and DELETE requests carrying an Idempotency-Key header safe to retry.
+// The first request with a key runs normally and its response is stored until IdempotencyTTL
+// passes. Retries get the stored response back, a retry with a different body gets 422.
+// Server errors are not stored, so the client can retry them for real.
+// Only authenticated requests are handled, unauthenticated ones and the auth routes pass through.
+func (cfg *ApiConfig) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodDelete) || notIdempotent[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope, ok := cfg.idempotencyScope(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, idempotentBodyLimit(r)))
+		maxBytesErr := &http.MaxBytesError{}
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, `{"error": "Request body is too large"}`, http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		claimed, err := cfg.Queries.ClaimIdempotencyKey(context.Background(), database.ClaimIdempotencyKeyParams{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(cfg.IdempotencyTTL),
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		if claimed == 0 {
+			stored, err := cfg.Queries.GetIdempotencyKey(context.Background(), database.GetIdempotencyKeyParams{
+				Scope: scope,
+				Key:   key,
+			})
+			if err != nil {
+				http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+				logger.Warn(err)
+				return
+			}
+
+			if time.Now().After(stored.ExpiresAt) {
+				err = cfg.Queries.DeleteIdempotencyKey(context.Background(), database.DeleteIdempotencyKeyParams{
+					Scope: scope,
+					Key:   key,
+				})
+				if err != nil {
+					http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+					logger.Warn(err)
+					return
+				}
+				cfg.Idempotent(next).ServeHTTP(w, r)
+				return
+			}
+
+			if stored.Fingerprint != fingerprint {
+				http.Error(w, `{"error": "Idempotency key was used with a different request"}`, http.StatusUnprocessableEntity)
+				return
+			}
+
+			if !stored.StatusCode.Valid {
+				http.Error(w, `{"error": "Request with this idempotency key is in progress"}`, http.StatusConflict)
+				return
+			}
+
+			headers := map[string]string{}
+			err = json.Unmarshal(stored.ResponseHeaders, &headers)
+			if err != nil {
+				http.Error(w, `{"error": "Problem with unmarshalling stored response"}`, http.StatusInternalServerError)
+				logger.Warn(err)
+				return
+			}
+			for name, value := range headers {
+				w.Header().Set(name, value)
+			}
+
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(int(stored.StatusCode.Int32))
+			w.Write(stored.ResponseBody)
+			return
+		}
+
+		// A panicking handler must not leave the key claimed, every retry would get 409 until it expires.
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			err := cfg.Queries.DeleteIdempotencyKey(context.Background(), database.DeleteIdempotencyKeyParams{
+				Scope: scope,
+				Key:   key,
+			})
+			logger.Warn(err, "problem with releasing idempotency key")
+			panic(recovered)
+		}()
+
+		rec := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		if rec.status >= http.StatusInternalServerError {
+			err = cfg.Queries.DeleteIdempotencyKey(context.Background(), database.DeleteIdempotencyKeyParams{
+				Scope: scope,
+				Key:   key,
+			})
+		} else {
+			err = cfg.saveIdempotentResponse(scope, key, rec)
+		}
+		logger.Warn(err, "problem with storing idempotent response")
+	})
+}
+
+// saveIdempotentResponse stores the recorded response under the key together with its replayedHeaders.
+func (cfg *ApiConfig) saveIdempotentResponse(scope, key string, rec *responseRecorder) error {
+	headers := map[string]string{}
+	for _, name := range replayedHeaders {
+		if value := rec.Header().Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+
+	rawHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+
+	return cfg.Queries.SaveIdempotentResponse(context.Background(), database.SaveIdempotentResponseParams{
+		Scope:           scope,
+		Key:             key,
+		StatusCode:      sql.NullInt32{Int32: int32(rec.status), Valid: true},
+		ResponseBody:    rec.body.Bytes(),
+		ResponseHeaders: rawHeaders,
+	})
+}
+
+func (cfg *ApiConfig) purgeIdempotencyKeys(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := cfg.Queries.DeleteExpiredIdempotencyKeys(context.Background())
+		logger.Warn(err, "problem with purging idempotency keys")
+	}
+}
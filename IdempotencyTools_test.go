@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// idempotentRequest sends a POST with the given idempotency key through the middleware.
+func idempotentRequest(handler http.Handler, token, key string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api/payments", strings.NewReader(`{}`))
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("Idempotency-Key", key)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestIdempotentReplaysHeaders(t *testing.T) {
+	cfg := newTestConfig(t)
+	_, token := newTestUser(t, cfg)
+
+	calls := 0
+	handler := cfg.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/api/payments/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+
+	key := uuid.NewString()
+	idempotentRequest(handler, token, key)
+	w := idempotentRequest(handler, token, key)
+
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want once", calls)
+	}
+	if w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatal("second response was not replayed")
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != `{"id": 1}` {
+		t.Fatalf("replayed %d %q", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("replayed Content-Type %q", got)
+	}
+	if got := w.Header().Get("Location"); got != "/api/payments/1" {
+		t.Fatalf("replayed Location %q", got)
+	}
+}
+
+func TestIdempotentReleasesKeyOnPanic(t *testing.T) {
+	cfg := newTestConfig(t)
+	_, token := newTestUser(t, cfg)
+
+	calls := 0
+	handler := cfg.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("handler failed")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	key := uuid.NewString()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panic did not reach the server")
+			}
+		}()
+		idempotentRequest(handler, token, key)
+	}()
+
+	w := idempotentRequest(handler, token, key)
+	if w.Code != http.StatusCreated || calls != 2 {
+		t.Fatalf("retry after panic got %d after %d calls, want the handler to run again", w.Code, calls)
+	}
+}
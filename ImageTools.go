@@ -0,0 +1,270 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/images"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/storage"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const imagesPath = "/images/"
+
+type ImageURLs struct {
+	ID        uuid.UUID `json:"id"`
+	Original  string    `json:"original"`
+	Thumbnail string    `json:"thumbnail"`
+	Medium    string    `json:"medium"`
+}
+
+func imageURLs(image database.ItemImage) ImageURLs {
+	return ImageURLs{
+		ID:        image.ID,
+		Original:  imagesPath + image.OriginalKey,
+		Thumbnail: imagesPath + image.ThumbnailKey,
+		Medium:    imagesPath + image.MediumKey,
+	}
+}
+
+// itemImageURLs groups the URLs of every stored image by item.
+func (cfg *ApiConfig) itemImageURLs(ctx context.Context) (map[uuid.UUID][]ImageURLs, error) {
+	itemImages, err := cfg.Queries.GetAllItemImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := map[uuid.UUID][]ImageURLs{}
+	for _, image := range itemImages {
+		urls[image.ItemID] = append(urls[image.ItemID], imageURLs(image))
+	}
+
+	return urls, nil
+}
+
+func (cfg *ApiConfig) HandlerUploadItemImage(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	_, err = cfg.Queries.GetItemById(context.Background(), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	// Leave room for the multipart framing around the file itself.
+	r.Body = http.MaxBytesReader(w, r.Body, images.MaxUploadSize+1<<20)
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, images.MaxUploadSize+1))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	processed, err := images.Process(data)
+	if errors.Is(err, images.ErrTooLarge) {
+		http.Error(w, `{"error": "Image is too large"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	if errors.Is(err, images.ErrUnsupportedType) {
+		http.Error(w, `{"error": "Only jpeg and png images are supported"}`, http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with processing image"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	imageID := uuid.New()
+	prefix := "items/" + itemID.String() + "/" + imageID.String() + "/"
+	args := database.CreateItemImageParams{
+		ID:           imageID,
+		ItemID:       itemID,
+		OriginalKey:  prefix + "original" + processed.Extension,
+		ThumbnailKey: prefix + "thumbnail.jpg",
+		MediumKey:    prefix + "medium.jpg",
+		ContentType:  processed.ContentType,
+	}
+
+	ctx := context.Background()
+	stored := []struct {
+		key         string
+		contentType string
+		data        []byte
+	}{
+		{args.OriginalKey, processed.ContentType, data},
+		{args.ThumbnailKey, "image/jpeg", processed.Thumbnail},
+		{args.MediumKey, "image/jpeg", processed.Medium},
+	}
+	for _, file := range stored {
+		err = cfg.Storage.Put(ctx, file.key, file.contentType, file.data)
+		if err != nil {
+			cfg.deleteImageFiles(args.OriginalKey, args.ThumbnailKey, args.MediumKey)
+			http.Error(w, `{"error": "Problem with storing image"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	image, err := cfg.Queries.CreateItemImage(ctx, args)
+	if err != nil {
+		cfg.deleteImageFiles(args.OriginalKey, args.ThumbnailKey, args.MediumKey)
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(imageURLs(image))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeleteItemImage(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	imageID, err := uuid.Parse(r.PathValue("imageID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	image, err := cfg.Queries.DeleteItemImage(context.Background(), database.DeleteItemImageParams{
+		ID:     imageID,
+		ItemID: itemID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Image not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	cfg.deleteImageFiles(image.OriginalKey, image.ThumbnailKey, image.MediumKey)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteImageFiles removes stored files on a best effort basis, a leftover file is only wasted space.
+func (cfg *ApiConfig) deleteImageFiles(keys ...string) {
+	for _, key := range keys {
+		err := cfg.Storage.Delete(context.Background(), key)
+		logger.Warn(err, "problem with deleting image file")
+	}
+}
+
+func (cfg *ApiConfig) HandlerServeImage(w http.ResponseWriter, r *http.Request) {
+	object, err := cfg.Storage.Get(r.Context(), r.PathValue("key"))
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, `{"error": "Image not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with loading image"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer object.Body.Close()
+
+	// Keys are never reused, so the files can be cached for good.
+	w.Header().Set("Content-Type", object.ContentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	_, err = io.Copy(w, object.Body)
+	logger.Warn(err, "problem with serving image")
+}
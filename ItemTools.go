@@ -0,0 +1,229 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Nutrition holds values per 100 g of the item, energy in kcal and everything else in grams.
+type Nutrition struct {
+	EnergyKcal    float64 `json:"energy_kcal"`
+	Protein       float64 `json:"protein"`
+	Carbohydrates float64 `json:"carbohydrates"`
+	Sugars        float64 `json:"sugars"`
+	Fat           float64 `json:"fat"`
+	Fiber         float64 `json:"fiber"`
+	Salt          float64 `json:"salt"`
+}
+
+type ItemDetails struct {
+	Description    string    `json:"description"`
+	OriginCountry  string    `json:"origin_country"`
+	IsOrganic      bool      `json:"is_organic"`
+	Certifications []string  `json:"certifications"`
+	Nutrition      Nutrition `json:"nutrition"`
+	Allergens      string    `json:"allergens"`
+	Tags           []string  `json:"tags"`
+}
+
+type ItemWithDetails struct {
+	database.GetItemDetailsRow
+	Season
+	Images     []ImageURLs       `json:"images"`
+	Components []BundleComponent `json:"components,omitempty"`
+}
+
+func (n Nutrition) valid() bool {
+	grams := []float64{n.Protein, n.Carbohydrates, n.Sugars, n.Fat, n.Fiber, n.Salt}
+	for _, value := range grams {
+		if value < 0 || value > 100 {
+			return false
+		}
+	}
+
+	return n.EnergyKcal >= 0 && n.Sugars <= n.Carbohydrates
+}
+
+func (cfg *ApiConfig) HandlerGetItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	item, err := cfg.Queries.GetItemDetails(context.Background(), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	itemImages, err := cfg.Queries.GetItemImages(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	components, err := cfg.Queries.GetBundleComponents(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	windows, err := cfg.Queries.GetItemSeasons(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	itemWithDetails := ItemWithDetails{
+		GetItemDetailsRow: item,
+		Season:            seasonOf(windows, time.Now()),
+		Images:            []ImageURLs{},
+	}
+	for _, image := range itemImages {
+		itemWithDetails.Images = append(itemWithDetails.Images, imageURLs(image))
+	}
+	for _, component := range components {
+		itemWithDetails.Components = append(itemWithDetails.Components, BundleComponent{
+			ItemID:   component.ItemID,
+			Quantity: int(component.Quantity),
+		})
+	}
+
+	respData, err := json.Marshal(itemWithDetails)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerUpdateItemDetails(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	details := ItemDetails{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&details)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if !details.Nutrition.valid() {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	certifications := []string{}
+	for _, certification := range details.Certifications {
+		if certification = strings.TrimSpace(certification); certification != "" {
+			certifications = append(certifications, certification)
+		}
+	}
+
+	tags := []string{}
+	for _, tag := range details.Tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	nutrition, err := json.Marshal(details.Nutrition)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	_, err = cfg.Queries.GetItemById(context.Background(), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	updated, err := cfg.Queries.UpsertItemDetails(context.Background(), database.UpsertItemDetailsParams{
+		ItemID:         itemID,
+		Description:    strings.TrimSpace(details.Description),
+		OriginCountry:  strings.TrimSpace(details.OriginCountry),
+		IsOrganic:      details.IsOrganic,
+		Certifications: certifications,
+		Nutrition:      nutrition,
+		Allergens:      strings.TrimSpace(details.Allergens),
+		Tags:           tags,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(updated)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/notifier"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type ReorderThreshold struct {
+	ReorderThreshold int `json:"reorder_threshold"`
+}
+
+// checkLowStock alerts the admin about items that fell to their reorder threshold.
+// An item is only reported once until its stock grows above the threshold again.
+func (cfg *ApiConfig) checkLowStock(itemIDs ...uuid.UUID) {
+	ctx := context.Background()
+
+	for _, itemID := range itemIDs {
+		components, err := cfg.Queries.GetBundleComponents(ctx, itemID)
+		if err != nil {
+			logger.Warn(err, "problem with loading bundle components")
+			continue
+		}
+
+		checked := []uuid.UUID{itemID}
+		if len(components) > 0 {
+			checked = checked[:0]
+			for _, component := range components {
+				checked = append(checked, component.ItemID)
+			}
+		}
+
+		for _, checkedID := range checked {
+			item, err := cfg.Queries.ClaimLowStockAlert(ctx, checkedID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				logger.Warn(err, "problem with claiming low stock alert")
+				continue
+			}
+
+			err = cfg.Notifier.Notify(ctx, notifier.Notification{
+				To:      cfg.AdminEmail,
+				Subject: fmt.Sprintf("%s is running low", item.Name),
+				Message: fmt.Sprintf("Only %d of %s left in stock, the reorder threshold is %d.", item.Quantity, item.Name, item.ReorderThreshold),
+			})
+			if err != nil {
+				logger.Warn(err, "problem with sending low stock alert")
+			}
+		}
+	}
+}
+
+func (cfg *ApiConfig) HandlerSetReorderThreshold(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	threshold := ReorderThreshold{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&threshold)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if threshold.ReorderThreshold < 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	item, err := cfg.Queries.SetReorderThreshold(context.Background(), database.SetReorderThresholdParams{
+		ID:               itemID,
+		ReorderThreshold: int32(threshold.ReorderThreshold),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.ClearLowStockAlert(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	go cfg.checkLowStock(itemID)
+
+	respData, err := json.Marshal(item)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/payment"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	pointsReasonEarn           = "earn"
+	pointsReasonRedeem         = "redeem"
+	pointsReasonRedeemReversal = "redeem_reversal"
+	pointsReasonRefund         = "refund_reversal"
+	pointsReasonExpire         = "expire"
+	pointsReasonReferral       = "referral"
+	pointsReasonReferralRefund = "referral_reversal"
+)
+
+// amountPerPoint is how much a customer pays to earn one point. A redeemed point
+// takes one unit off the total.
+const amountPerPoint = 100
+
+var errNotEnoughPoints = errors.New("not enough loyalty points")
+
+type PointsRequest struct {
+	Points int `json:"points"`
+}
+
+type LoyaltyPoints struct {
+	Balance   int32                           `json:"balance"`
+	Movements []database.LoyaltyPointMovement `json:"movements"`
+}
+
+func recordPointMovement(ctx context.Context, qtx *database.Queries, userID uuid.UUID, delta int32, reason, reference string) error {
+	return qtx.InsertPointMovement(ctx, database.InsertPointMovementParams{
+		UserID:    userID,
+		Delta:     delta,
+		Reason:    reason,
+		Reference: reference,
+	})
+}
+
+// earnPoints credits the points of a succeeded payment as a new lot that expires after ttl.
+func earnPoints(ctx context.Context, qtx *database.Queries, paid database.Payment, ttl time.Duration) error {
+	points := paid.Amount / amountPerPoint
+	if points <= 0 {
+		return nil
+	}
+
+	_, err := qtx.CreatePointLot(ctx, database.CreatePointLotParams{
+		UserID:    paid.UserID,
+		PaymentID: uuid.NullUUID{UUID: paid.ID, Valid: true},
+		Points:    points,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordPointMovement(ctx, qtx, paid.UserID, points, pointsReasonEarn, "payment:"+paid.ID.String())
+}
+
+// spendPoints takes points from the lots that expire first and reports how many it took.
+// With partial set it takes whatever is left instead of failing with errNotEnoughPoints.
+// The caller records the movement.
+func spendPoints(ctx context.Context, qtx *database.Queries, userID uuid.UUID, points int32, partial bool) (int32, error) {
+	taken := int32(0)
+	for taken < points {
+		lot, err := qtx.GetOldestPointLot(ctx, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			if partial {
+				return taken, nil
+			}
+			return 0, errNotEnoughPoints
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		amount := min(points-taken, lot.Remaining)
+		err = qtx.TakePointLot(ctx, database.TakePointLotParams{
+			Amount: amount,
+			ID:     lot.ID,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		taken += amount
+	}
+
+	return taken, nil
+}
+
+// redeemPoints spends the points that paid for part of a payment.
+func redeemPoints(ctx context.Context, qtx *database.Queries, paymentRow database.Payment, points int32) error {
+	if points <= 0 {
+		return nil
+	}
+
+	_, err := spendPoints(ctx, qtx, paymentRow.UserID, points, false)
+	if err != nil {
+		return err
+	}
+
+	return recordPointMovement(ctx, qtx, paymentRow.UserID, -points, pointsReasonRedeem, "payment:"+paymentRow.ID.String())
+}
+
+// returnRedeemedPoints gives back the points spent on a payment that did not go through.
+// They come back as a fresh lot, so the customer gets the full period to use them again.
+func returnRedeemedPoints(ctx context.Context, qtx *database.Queries, paymentRow database.Payment, ttl time.Duration) error {
+	reference := "payment:" + paymentRow.ID.String()
+
+	redeemed, err := qtx.GetRedeemedPoints(ctx, database.GetRedeemedPointsParams{
+		UserID:    paymentRow.UserID,
+		Reference: reference,
+	})
+	if err != nil {
+		return err
+	}
+	if redeemed <= 0 {
+		return nil
+	}
+
+	_, err = qtx.CreatePointLot(ctx, database.CreatePointLotParams{
+		UserID:    paymentRow.UserID,
+		Points:    redeemed,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	return recordPointMovement(ctx, qtx, paymentRow.UserID, redeemed, pointsReasonRedeemReversal, reference)
+}
+
+// reversePaymentPoints takes back the points a payment earned in proportion to the refunded amount.
+// Points come off the payment's own lot first. Points already spent can not be taken back,
+// so the reversal stops at the customer's balance.
+func reversePaymentPoints(ctx context.Context, qtx *database.Queries, paid database.Payment, refundID uuid.UUID, amount int32) error {
+	lot, err := qtx.GetPaymentPointLot(ctx, uuid.NullUUID{UUID: paid.ID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	points := int32(int64(lot.Points) * int64(amount) / int64(paid.Amount))
+	if points <= 0 {
+		return nil
+	}
+
+	fromLot := int32(0)
+	if !lot.ExpiredAt.Valid && lot.ExpiresAt.After(time.Now()) {
+		fromLot = min(points, lot.Remaining)
+	}
+	if fromLot > 0 {
+		err = qtx.TakePointLot(ctx, database.TakePointLotParams{
+			Amount: fromLot,
+			ID:     lot.ID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rest, err := spendPoints(ctx, qtx, paid.UserID, points-fromLot, true)
+	if err != nil {
+		return err
+	}
+
+	if fromLot+rest == 0 {
+		return nil
+	}
+
+	return recordPointMovement(ctx, qtx, paid.UserID, -(fromLot + rest), pointsReasonRefund, "refund:"+refundID.String())
+}
+
+// settlePayment moves a pending payment to the status reported by the provider. A payment that
+// succeeded earns loyalty points, completes a pending referral and books its delivery slot.
+// One that failed gives back the points and store credit spent on it and releases the slot.
+func (cfg *ApiConfig) settlePayment(ctx context.Context, qtx *database.Queries, intentID sql.NullString, status string) error {
+	updated, err := qtx.UpdatePendingPaymentStatus(ctx, database.UpdatePendingPaymentStatusParams{
+		ProviderIntentID: intentID,
+		Status:           status,
+	})
+	if err != nil || updated == 0 {
+		return err
+	}
+
+	settled, err := qtx.GetPaymentByIntent(ctx, intentID)
+	if err != nil {
+		return err
+	}
+
+	switch status {
+	case payment.StatusSucceeded:
+		err = earnPoints(ctx, qtx, settled, cfg.PointsTTL)
+		if err != nil {
+			return err
+		}
+
+		err = cfg.rewardReferral(ctx, qtx, settled)
+		if err != nil {
+			return err
+		}
+
+		return bookPaymentSlot(ctx, qtx, settled)
+	case payment.StatusFailed:
+		err = returnPaymentStock(ctx, qtx, settled)
+		if err != nil {
+			return err
+		}
+
+		err = returnCoupon(ctx, qtx, settled)
+		if err != nil {
+			return err
+		}
+
+		err = returnRedeemedPoints(ctx, qtx, settled, cfg.PointsTTL)
+		if err != nil {
+			return err
+		}
+
+		err = returnStoreCredit(ctx, qtx, settled)
+		if err != nil {
+			return err
+		}
+
+		return releasePaymentSlot(ctx, qtx, settled)
+	}
+
+	return nil
+}
+
+// cartPoints is how many points the user wants to spend on the cart, limited by what they have.
+func cartPoints(ctx context.Context, q *database.Queries, userID uuid.UUID) (int, error) {
+	points, err := q.GetCartPointRedemption(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	balance, err := q.GetPointBalance(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(min(points, balance)), nil
+}
+
+func (cfg *ApiConfig) expireLoyaltyPoints(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := cfg.expirePoints(context.Background())
+		logger.Warn(err, "problem with expiring loyalty points")
+	}
+}
+
+// expirePoints closes every lot past its expiry date and writes off the points left in it.
+func (cfg *ApiConfig) expirePoints(ctx context.Context) error {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	lots, err := qtx.ExpirePointLots(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, lot := range lots {
+		if lot.Remaining == 0 {
+			continue
+		}
+
+		err = recordPointMovement(ctx, qtx, lot.UserID, -lot.Remaining, pointsReasonExpire, "lot:"+lot.ID.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cfg *ApiConfig) HandlerGetPoints(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	balance, err := cfg.Queries.GetPointBalance(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	movements, err := cfg.Queries.GetPointMovements(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if movements == nil {
+		movements = []database.LoyaltyPointMovement{}
+	}
+
+	respData, err := json.Marshal(LoyaltyPoints{
+		Balance:   balance,
+		Movements: movements,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerRedeemPoints(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	pointsRequest := PointsRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&pointsRequest)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if pointsRequest.Points <= 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	balance, err := cfg.Queries.GetPointBalance(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if int32(pointsRequest.Points) > balance {
+		http.Error(w, `{"error": "Not enough loyalty points"}`, http.StatusBadRequest)
+		return
+	}
+
+	err = cfg.Queries.SetCartPointRedemption(context.Background(), database.SetCartPointRedemptionParams{
+		UserID: userID,
+		Points: int32(pointsRequest.Points),
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	pricing, err := cfg.priceCart(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(pricing)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerRemovePoints(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteCartPointRedemption(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
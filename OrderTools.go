@@ -0,0 +1,142 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/payment"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Order is a payment as the customer sees it: what was bought and what was refunded.
+type Order struct {
+	database.Payment
+	Lines        []OrderLine       `json:"lines"`
+	Refunds      []database.Refund `json:"refunds"`
+	Refunded     int               `json:"refunded"`
+	RefundStatus string            `json:"refund_status"`
+}
+
+type OrderLine struct {
+	ItemID           uuid.UUID `json:"item_id"`
+	Name             string    `json:"name"`
+	Quantity         int       `json:"quantity"`
+	Cost             int       `json:"cost"`
+	RefundedQuantity int       `json:"refunded_quantity"`
+}
+
+const (
+	orderNotRefunded       = "none"
+	orderRefundPending     = "pending"
+	orderPartiallyRefunded = "partially_refunded"
+	orderRefunded          = "refunded"
+)
+
+// orderRefundStatus sums up the refunds of an order. A refund still on its way to the
+// provider wins, the customer should not read the order as settled yet.
+func orderRefundStatus(paymentRow database.Payment, refunds []database.Refund) (int, string) {
+	refunded := 0
+	pending := false
+	for _, refund := range refunds {
+		if refund.Status == payment.StatusFailed {
+			continue
+		}
+		refunded += int(refund.Amount)
+		if refund.Status != payment.StatusSucceeded {
+			pending = true
+		}
+	}
+
+	switch {
+	case pending:
+		return refunded, orderRefundPending
+	case refunded == 0:
+		return refunded, orderNotRefunded
+	case refunded < int(paymentRow.Amount+paymentRow.StoreCredit):
+		return refunded, orderPartiallyRefunded
+	}
+
+	return refunded, orderRefunded
+}
+
+func (cfg *ApiConfig) HandlerGetOrder(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("orderID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	userPayment, err := cfg.Queries.GetUserPayment(context.Background(), database.GetUserPaymentParams{
+		ID:     orderID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Order not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	lines, err := cfg.Queries.GetOrderLines(context.Background(), userPayment.ID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	refunds, err := cfg.Queries.GetPaymentRefunds(context.Background(), userPayment.ID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	order := Order{
+		Payment: userPayment,
+		Lines:   make([]OrderLine, 0, len(lines)),
+		Refunds: refunds,
+	}
+	for _, line := range lines {
+		order.Lines = append(order.Lines, OrderLine{
+			ItemID:           line.ItemID,
+			Name:             line.Name,
+			Quantity:         int(line.Quantity),
+			Cost:             int(line.Cost),
+			RefundedQuantity: int(line.RefundedQuantity),
+		})
+	}
+	order.Refunded, order.RefundStatus = orderRefundStatus(userPayment, refunds)
+
+	respData, err := json.Marshal(order)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
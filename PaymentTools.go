@@ -0,0 +1,550 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/delivery"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/payment"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const maxWebhookSize = 1 << 20
+
+type PaymentRequest struct {
+	PaymentMethod  string    `json:"payment_method"`
+	UseStoreCredit bool      `json:"use_store_credit"`
+	AddressID      uuid.UUID `json:"address_id"`
+}
+
+func (cfg *ApiConfig) HandlerCreatePayment(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	paymentRequest := PaymentRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&paymentRequest)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	pricing, err := cfg.priceCart(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if pricing.Total <= 0 {
+		http.Error(w, `{"error": "Shopping cart is empty"}`, http.StatusBadRequest)
+		return
+	}
+
+	if paymentRequest.AddressID == uuid.Nil {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	address, err := cfg.Queries.GetUserAddress(context.Background(), database.GetUserAddressParams{
+		ID:     paymentRequest.AddressID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Address not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	zone, err := cfg.deliveryZoneFor(context.Background(), address)
+	if errors.Is(err, delivery.ErrOutsideZones) {
+		http.Error(w, `{"error": "Address is outside of delivery zones"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	// The delivery fee is charged on top of the cart, like the quote shows it.
+	fee := zone.FeeFor(pricing.Total)
+	total := pricing.Total + fee
+
+	// Store credit pays first, the payment provider is charged only for the rest.
+	credit := 0
+	if paymentRequest.UseStoreCredit {
+		balance, err := cfg.Queries.GetStoreCreditBalance(context.Background(), userID)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		credit = min(int(balance), total)
+	}
+	amount := total - credit
+
+	if amount > 0 && paymentRequest.PaymentMethod == "" {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	// The slot held by the cart moves to the payment, its capacity stays taken.
+	slotID := uuid.NullUUID{}
+	slot, err := qtx.GetCartSlot(context.Background(), userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if err == nil {
+		if slot.ZoneID != zone.ID {
+			http.Error(w, `{"error": "Delivery slot does not serve the address"}`, http.StatusBadRequest)
+			return
+		}
+
+		_, err = qtx.DeleteCartSlot(context.Background(), userID)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		slotID = uuid.NullUUID{UUID: slot.ID, Valid: true}
+	}
+
+	newPayment, err := qtx.CreatePayment(context.Background(), database.CreatePaymentParams{
+		UserID:      userID,
+		Amount:      int32(amount),
+		StoreCredit: int32(credit),
+		AddressID:   uuid.NullUUID{UUID: address.ID, Valid: true},
+		DeliveryFee: int32(fee),
+		SlotID:      slotID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = qtx.SnapshotPaymentItems(context.Background(), database.SnapshotPaymentItemsParams{
+		PaymentID: newPayment.ID,
+		UserID:    userID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	// The cart lines now belong to the payment, their stock stays taken.
+	err = qtx.ClearShoppingCart(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = qtx.ClearCartBatchAllocations(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = qtx.ClearBoxReservations(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	newPayment, err = redeemCoupon(context.Background(), qtx, newPayment, pricing)
+	if errors.Is(err, errCouponLimit) {
+		http.Error(w, `{"error": "Coupon usage limit reached"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = redeemPoints(context.Background(), qtx, newPayment, int32(pricing.PointsDiscount))
+	if errors.Is(err, errNotEnoughPoints) {
+		http.Error(w, `{"error": "Not enough loyalty points"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = qtx.DeleteCartPointRedemption(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if credit > 0 {
+		err = takeStoreCredit(context.Background(), qtx, userID, int32(credit), creditMovement{
+			Reason:    creditReasonCheckout,
+			ActorID:   uuid.NullUUID{UUID: userID, Valid: true},
+			Reference: "payment:" + newPayment.ID.String(),
+		})
+		if errors.Is(err, errNotEnoughCredit) {
+			http.Error(w, `{"error": "Not enough store credit"}`, http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	// Paid in full with store credit, there is nothing to send to the payment provider.
+	if amount == 0 {
+		newPayment, err = qtx.SetPaymentIntent(context.Background(), database.SetPaymentIntentParams{
+			ID:     newPayment.ID,
+			Status: payment.StatusSucceeded,
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		err = earnPoints(context.Background(), qtx, newPayment, cfg.PointsTTL)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		err = cfg.rewardReferral(context.Background(), qtx, newPayment)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		err = bookPaymentSlot(context.Background(), qtx, newPayment)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if amount == 0 {
+		respData, err := json.Marshal(newPayment)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write(respData)
+		return
+	}
+
+	intent, err := cfg.Payments.CreateIntent(context.Background(), payment.IntentParams{
+		Amount:        amount,
+		Reference:     newPayment.ID.String(),
+		PaymentMethod: paymentRequest.PaymentMethod,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with payment provider"}`, http.StatusBadGateway)
+		logger.Warn(err)
+
+		// The payment never reached the provider, its items, coupon, points and store credit go back.
+		err = returnPaymentStock(context.Background(), cfg.Queries, newPayment)
+		logger.Warn(err, "problem with returning payment stock")
+
+		err = returnCoupon(context.Background(), cfg.Queries, newPayment)
+		logger.Warn(err, "problem with returning coupon")
+
+		err = returnRedeemedPoints(context.Background(), cfg.Queries, newPayment, cfg.PointsTTL)
+		logger.Warn(err, "problem with returning redeemed points")
+
+		err = returnStoreCredit(context.Background(), cfg.Queries, newPayment)
+		logger.Warn(err, "problem with returning store credit")
+
+		err = releasePaymentSlot(context.Background(), cfg.Queries, newPayment)
+		logger.Warn(err, "problem with releasing delivery slot")
+		return
+	}
+
+	newPayment, err = cfg.Queries.SetPaymentIntent(context.Background(), database.SetPaymentIntentParams{
+		ID:               newPayment.ID,
+		ProviderIntentID: sql.NullString{String: intent.ID, Valid: true},
+		Status:           intent.Status,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(newPayment)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCapturePayment(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	paymentID, err := uuid.Parse(r.PathValue("paymentID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	userPayment, err := cfg.Queries.GetUserPayment(context.Background(), database.GetUserPaymentParams{
+		ID:     paymentID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !userPayment.ProviderIntentID.Valid) {
+		http.Error(w, `{"error": "Payment not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	intent, err := cfg.Payments.Capture(context.Background(), userPayment.ProviderIntentID.String)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with payment provider"}`, http.StatusBadGateway)
+		logger.Warn(err)
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	err = cfg.settlePayment(context.Background(), cfg.Queries.WithTx(tx), userPayment.ProviderIntentID, intent.Status)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	userPayment.Status = intent.Status
+
+	respData, err := json.Marshal(userPayment)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetPayment(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	paymentID, err := uuid.Parse(r.PathValue("paymentID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	userPayment, err := cfg.Queries.GetUserPayment(context.Background(), database.GetUserPaymentParams{
+		ID:     paymentID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Payment not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	refunds, err := cfg.Queries.GetPaymentRefunds(context.Background(), userPayment.ID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(PaymentWithRefunds{
+		Payment: userPayment,
+		Refunds: refunds,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+// HandlerPaymentWebhook applies provider events. Every event id is stored together with its
+// effect in one transaction, so a redelivered event is acknowledged without being applied twice.
+func (cfg *ApiConfig) HandlerPaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookSize))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	event, err := cfg.Payments.VerifyWebhook(payload, r.Header.Get("X-Payment-Signature"))
+	if err != nil {
+		http.Error(w, `{"error": "Invalid webhook"}`, http.StatusBadRequest)
+		logger.Warn(err, "payment webhook rejected")
+		return
+	}
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	inserted, err := qtx.InsertPaymentEvent(context.Background(), database.InsertPaymentEventParams{
+		ID:               event.ID,
+		Type:             event.Type,
+		ProviderIntentID: event.IntentID,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if inserted == 0 {
+		logger.Info("Duplicate payment webhook " + event.ID + " skipped")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	intentID := sql.NullString{String: event.IntentID, Valid: true}
+
+	switch event.Type {
+	case payment.EventPaymentSucceeded:
+		err = cfg.settlePayment(context.Background(), qtx, intentID, payment.StatusSucceeded)
+	case payment.EventPaymentFailed:
+		err = cfg.settlePayment(context.Background(), qtx, intentID, payment.StatusFailed)
+	case payment.EventRefundSucceeded:
+		_, err = qtx.UpdateRefundStatus(context.Background(), database.UpdateRefundStatusParams{
+			ProviderRefundID: sql.NullString{String: event.RefundID, Valid: true},
+			Status:           payment.StatusSucceeded,
+		})
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/payment"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCreatePaymentClearsCart(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	user, token := newTestUser(t, cfg)
+	item := newTestItem(t, cfg, 5)
+
+	postcode := uuid.NewString()[:8]
+	_, err := cfg.Queries.CreateDeliveryZone(ctx, database.CreateDeliveryZoneParams{
+		Name:      "test zone " + postcode,
+		Postcodes: []string{postcode},
+		Polygon:   json.RawMessage(`[]`),
+		Fee:       100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := cfg.Queries.CreateAddress(ctx, database.CreateAddressParams{
+		UserID:   user.ID,
+		Label:    "home",
+		Street:   "Test street 1",
+		City:     "Test city",
+		Postcode: postcode,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itemPath := map[string]string{"itemID": item.ID.String()}
+
+	w := serveTest(t, cfg.HandlerGetInCart, http.MethodPost, "/api/item/"+item.ID.String(), token, map[string]int{"quantity": 2}, itemPath)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("adding to cart: got status %d: %s", w.Code, w.Body)
+	}
+
+	w = serveTest(t, cfg.HandlerCreatePayment, http.MethodPost, "/api/payments", token, PaymentRequest{
+		PaymentMethod: payment.MockMethodSuccess,
+		AddressID:     address.ID,
+	}, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating payment: got status %d: %s", w.Code, w.Body)
+	}
+
+	cart, err := cfg.Queries.GetShoppingCart(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart) != 0 {
+		t.Fatalf("cart has %d lines after payment, want none", len(cart))
+	}
+
+	w = serveTest(t, cfg.HandlerDeleteFromCart, http.MethodDelete, "/api/delete/"+item.ID.String(), token, nil, itemPath)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("deleting a paid line: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	stock, err := cfg.Queries.GetItemById(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.Quantity != 3 {
+		t.Fatalf("stock is %d after paying for 2 of 5, want 3", stock.Quantity)
+	}
+}
+
+func TestGetInCartRejectsNonPositiveQuantity(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	_, token := newTestUser(t, cfg)
+	item := newTestItem(t, cfg, 5)
+
+	for _, quantity := range []int{0, -3} {
+		w := serveTest(t, cfg.HandlerGetInCart, http.MethodPost, "/api/item/"+item.ID.String(), token, map[string]int{"quantity": quantity}, map[string]string{"itemID": item.ID.String()})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("quantity %d: got status %d, want %d", quantity, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	stock, err := cfg.Queries.GetItemById(context.Background(), item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock.Quantity != 5 {
+		t.Fatalf("stock is %d after rejected requests, want 5", stock.Quantity)
+	}
+}
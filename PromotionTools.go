@@ -0,0 +1,413 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/promotions"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Promotion struct {
+	Name         string     `json:"name"`
+	RuleType     string     `json:"rule_type"`
+	ItemID       *uuid.UUID `json:"item_id"`
+	Category     string     `json:"category"`
+	Percent      int        `json:"percent"`
+	Amount       int        `json:"amount"`
+	BuyQuantity  int        `json:"buy_quantity"`
+	FreeQuantity int        `json:"free_quantity"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+}
+
+func (p Promotion) toRule() promotions.Rule {
+	rule := promotions.Rule{
+		Name:         p.Name,
+		Type:         p.RuleType,
+		Category:     p.Category,
+		Percent:      p.Percent,
+		Amount:       p.Amount,
+		BuyQuantity:  p.BuyQuantity,
+		FreeQuantity: p.FreeQuantity,
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+	}
+	if p.ItemID != nil {
+		rule.ItemID = uuid.NullUUID{UUID: *p.ItemID, Valid: true}
+	}
+
+	return rule
+}
+
+func promotionToRule(p database.Promotion) promotions.Rule {
+	return promotions.Rule{
+		ID:           p.ID,
+		Name:         p.Name,
+		Type:         p.RuleType,
+		ItemID:       p.ItemID,
+		Category:     p.Category,
+		Percent:      int(p.Percent),
+		Amount:       int(p.Amount),
+		BuyQuantity:  int(p.BuyQuantity),
+		FreeQuantity: int(p.FreeQuantity),
+		StartsAt:     p.StartsAt,
+		EndsAt:       p.EndsAt,
+	}
+}
+
+// priceCart prices the user's shopping cart with active promotions first, then the coupon attached
+// to the cart, then the loyalty points the user chose to spend.
+func (cfg *ApiConfig) priceCart(ctx context.Context, userID uuid.UUID) (promotions.Result, error) {
+	res, err := cfg.priceCartCoupon(ctx, userID)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	points, err := cartPoints(ctx, cfg.Queries, userID)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	return promotions.ApplyPoints(res, points), nil
+}
+
+func (cfg *ApiConfig) priceCartCoupon(ctx context.Context, userID uuid.UUID) (promotions.Result, error) {
+	res, err := cfg.priceCartPromotions(ctx, userID)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	coupon, err := cfg.Queries.GetCartCoupon(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return res, nil
+	}
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	withCoupon, err := promotions.ApplyCoupon(res, couponToPromotions(coupon), time.Now())
+	if err != nil {
+		// The coupon stays attached to the cart, it just does not apply until the basket qualifies again.
+		return res, nil
+	}
+
+	return withCoupon, nil
+}
+
+func (cfg *ApiConfig) priceCartPromotions(ctx context.Context, userID uuid.UUID) (promotions.Result, error) {
+	cart, err := cfg.Queries.GetShoppingCartForPricing(ctx, userID)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	activePromotions, err := cfg.Queries.GetActivePromotions(ctx)
+	if err != nil {
+		return promotions.Result{}, err
+	}
+
+	lines := make([]promotions.Line, 0, len(cart))
+	for _, line := range cart {
+		lines = append(lines, promotions.Line{
+			ItemID:   line.ItemID,
+			Category: line.Category,
+			Quantity: int(line.Quantity),
+			Cost:     int(line.Cost),
+		})
+	}
+
+	rules := make([]promotions.Rule, 0, len(activePromotions))
+	for _, p := range activePromotions {
+		rules = append(rules, promotionToRule(p))
+	}
+
+	return promotions.Apply(lines, rules, time.Now()), nil
+}
+
+// HandlerGetCartPricing returns the subtotal, discount, total and applied promotions of the user's
+// shopping cart. The cart lines themselves stay on GET /api/shopping_cart.
+func (cfg *ApiConfig) HandlerGetCartPricing(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	pricing, err := cfg.priceCart(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(pricing)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetPromotions(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	allPromotions, err := cfg.Queries.GetAllPromotions(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(allPromotions)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreatePromotion(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	newPromotion := Promotion{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newPromotion)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	rule := newPromotion.toRule()
+	err = promotions.Validate(rule)
+	if err != nil {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	createdPromotion, err := cfg.Queries.CreatePromotion(context.Background(), database.CreatePromotionParams{
+		Name:         rule.Name,
+		RuleType:     rule.Type,
+		ItemID:       rule.ItemID,
+		Category:     rule.Category,
+		Percent:      int32(rule.Percent),
+		Amount:       int32(rule.Amount),
+		BuyQuantity:  int32(rule.BuyQuantity),
+		FreeQuantity: int32(rule.FreeQuantity),
+		StartsAt:     rule.StartsAt,
+		EndsAt:       rule.EndsAt,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(createdPromotion)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerUpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	promotionID, err := uuid.Parse(r.PathValue("promotionID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	updatedPromotion := Promotion{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&updatedPromotion)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	rule := updatedPromotion.toRule()
+	err = promotions.Validate(rule)
+	if err != nil {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	promotion, err := cfg.Queries.UpdatePromotion(context.Background(), database.UpdatePromotionParams{
+		ID:           promotionID,
+		Name:         rule.Name,
+		RuleType:     rule.Type,
+		ItemID:       rule.ItemID,
+		Category:     rule.Category,
+		Percent:      int32(rule.Percent),
+		Amount:       int32(rule.Amount),
+		BuyQuantity:  int32(rule.BuyQuantity),
+		FreeQuantity: int32(rule.FreeQuantity),
+		StartsAt:     rule.StartsAt,
+		EndsAt:       rule.EndsAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Promotion not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(promotion)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeletePromotion(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	promotionID, err := uuid.Parse(r.PathValue("promotionID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeletePromotion(context.Background(), promotionID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
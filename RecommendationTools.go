@@ -0,0 +1,129 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	recommendationsPerItem = 20
+	defaultRecommendations = 10
+)
+
+func (cfg *ApiConfig) runRecommendations(interval time.Duration) {
+	for range time.Tick(interval) {
+		err := cfg.refreshRecommendations(context.Background())
+		logger.Warn(err, "problem with refreshing recommendations")
+	}
+}
+
+// refreshRecommendations rebuilds the "you may also like" pairs from the items customers bought
+// together and the "buy again" lists from each customer's own purchases. Requests only read
+// the stored results, so the heavy aggregation never runs on the request path.
+func (cfg *ApiConfig) refreshRecommendations(ctx context.Context) error {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	err = qtx.DeleteItemRecommendations(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.RefreshItemRecommendations(ctx, recommendationsPerItem)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.DeleteBuyAgainRecommendations(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = qtx.RefreshBuyAgainRecommendations(ctx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (cfg *ApiConfig) HandlerGetItemRecommendations(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	recommendations, err := cfg.Queries.GetItemRecommendations(context.Background(), database.GetItemRecommendationsParams{
+		ItemID:     itemID,
+		MaxResults: defaultRecommendations,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if recommendations == nil {
+		recommendations = []database.GetItemRecommendationsRow{}
+	}
+
+	respData, err := json.Marshal(recommendations)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetBuyAgain(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	recommendations, err := cfg.Queries.GetBuyAgainRecommendations(context.Background(), database.GetBuyAgainRecommendationsParams{
+		UserID:     userID,
+		MaxResults: defaultRecommendations,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if recommendations == nil {
+		recommendations = []database.GetBuyAgainRecommendationsRow{}
+	}
+
+	respData, err := json.Marshal(recommendations)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	referralPending  = "pending"
+	referralRewarded = "rewarded"
+	referralRejected = "rejected"
+	referralReversed = "reversed"
+)
+
+const (
+	referralRejectedSelf          = "self_referral"
+	referralRejectedSharedAddress = "shared_address"
+	referralReversedRefund        = "refunded"
+)
+
+const referralCodeLength = 8
+
+// referralRewardPoints is what both the referrer and the referee earn once the referee pays for
+// their first order.
+const referralRewardPoints = 500
+
+// referralMinOrderTotal is the smallest payment, provider part and store credit together,
+// that completes a referral. Smaller payments leave it pending.
+const referralMinOrderTotal = 1000
+
+type UserReferrals struct {
+	Code      string              `json:"code"`
+	Referrals []database.Referral `json:"referrals"`
+}
+
+// canonicalEmail strips what people add to one mailbox to make it look like another:
+// letter case, a +tag and, for Gmail, dots in the local part.
+func canonicalEmail(email string) string {
+	local, domain, found := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !found {
+		return local
+	}
+
+	local, _, _ = strings.Cut(local, "+")
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+
+	return local + "@" + domain
+}
+
+// ensureReferralCode gives the user a referral code unless they already have one and returns it.
+func ensureReferralCode(ctx context.Context, qtx *database.Queries, userID uuid.UUID) (string, error) {
+	code, err := randomCode(referralCodeLength)
+	if err != nil {
+		return "", err
+	}
+
+	err = qtx.CreateReferralCode(ctx, database.CreateReferralCodeParams{
+		UserID: userID,
+		Code:   code,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return qtx.GetReferralCode(ctx, userID)
+}
+
+// createReferral records that referee registered with the code of referrer. A referee who turns
+// out to be the referrer under another address is recorded as rejected straight away.
+func createReferral(ctx context.Context, qtx *database.Queries, referrer database.GetReferrerByCodeRow, referee database.User) error {
+	status := referralPending
+	reason := ""
+	if canonicalEmail(referrer.Email) == canonicalEmail(referee.Email) {
+		status = referralRejected
+		reason = referralRejectedSelf
+	}
+
+	_, err := qtx.CreateReferral(ctx, database.CreateReferralParams{
+		ReferrerID:      referrer.UserID,
+		RefereeID:       referee.ID,
+		Status:          status,
+		RejectionReason: reason,
+	})
+
+	return err
+}
+
+// rewardReferral completes the pending referral of the user who made paid, their first succeeded
+// payment of at least referralMinOrderTotal. Both sides get referralRewardPoints unless the referee
+// delivers to an address the referrer also uses.
+func (cfg *ApiConfig) rewardReferral(ctx context.Context, qtx *database.Queries, paid database.Payment) error {
+	if paid.Amount+paid.StoreCredit < referralMinOrderTotal {
+		return nil
+	}
+
+	referral, err := qtx.GetPendingReferral(ctx, paid.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	shared, err := qtx.SharesAddress(ctx, database.SharesAddressParams{
+		ReferrerID: referral.ReferrerID,
+		RefereeID:  referral.RefereeID,
+	})
+	if err != nil {
+		return err
+	}
+
+	completed := database.CompleteReferralParams{
+		ID:        referral.ID,
+		Status:    referralRewarded,
+		PaymentID: uuid.NullUUID{UUID: paid.ID, Valid: true},
+	}
+	if shared {
+		completed.Status = referralRejected
+		completed.RejectionReason = referralRejectedSharedAddress
+	}
+
+	err = qtx.CompleteReferral(ctx, completed)
+	if err != nil || shared {
+		return err
+	}
+
+	for _, userID := range []uuid.UUID{referral.ReferrerID, referral.RefereeID} {
+		_, err = qtx.CreatePointLot(ctx, database.CreatePointLotParams{
+			UserID:    userID,
+			Points:    referralRewardPoints,
+			ExpiresAt: time.Now().Add(cfg.PointsTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordPointMovement(ctx, qtx, userID, referralRewardPoints, pointsReasonReferral, "referral:"+referral.ID.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reverseReferral takes back the rewards of the referral paid completed once refunds bring it below
+// referralMinOrderTotal. Like other reversals it stops at what each side still has.
+func reverseReferral(ctx context.Context, qtx *database.Queries, paid database.Payment, refunded int32) error {
+	if paid.Amount+paid.StoreCredit-refunded >= referralMinOrderTotal {
+		return nil
+	}
+
+	referral, err := qtx.GetRewardedReferralByPayment(ctx, uuid.NullUUID{UUID: paid.ID, Valid: true})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range []uuid.UUID{referral.ReferrerID, referral.RefereeID} {
+		taken, err := spendPoints(ctx, qtx, userID, referralRewardPoints, true)
+		if err != nil {
+			return err
+		}
+		if taken == 0 {
+			continue
+		}
+
+		err = recordPointMovement(ctx, qtx, userID, -taken, pointsReasonReferralRefund, "referral:"+referral.ID.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return qtx.CompleteReferral(ctx, database.CompleteReferralParams{
+		ID:              referral.ID,
+		Status:          referralReversed,
+		RejectionReason: referralReversedRefund,
+		PaymentID:       referral.PaymentID,
+	})
+}
+
+func (cfg *ApiConfig) HandlerGetReferral(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	// Users registered before referrals existed get their code on first request.
+	code, err := ensureReferralCode(context.Background(), cfg.Queries, userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	referrals, err := cfg.Queries.GetUserReferrals(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if referrals == nil {
+		referrals = []database.Referral{}
+	}
+
+	respData, err := json.Marshal(UserReferrals{
+		Code:      code,
+		Referrals: referrals,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetReferrals(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	referrals, err := cfg.Queries.GetReferrals(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(referrals)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/payment"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type RefundRequest struct {
+	Amount int          `json:"amount"`
+	Reason string       `json:"reason"`
+	Lines  []RefundLine `json:"lines"`
+}
+
+// RefundLine refunds units of an order line, Restock puts them back on sale.
+type RefundLine struct {
+	ItemID   uuid.UUID `json:"item_id"`
+	Quantity int       `json:"quantity"`
+	Restock  bool      `json:"restock"`
+}
+
+type PaymentWithRefunds struct {
+	database.Payment
+	Refunds []database.Refund `json:"refunds"`
+}
+
+// refundPending is the status of a refund that has not reached the payment provider yet.
+const refundPending = "pending"
+
+var (
+	errPaymentNotCaptured = errors.New("payment is not captured")
+	errRefundTooLarge     = errors.New("refund exceeds payment amount")
+	errRefundLineTooLarge = errors.New("refund exceeds what the payment bought")
+)
+
+// lineRefundAmount is what quantity units of an order line cost the customer. Cart discounts
+// are spread over the lines, so the line gets its share of the goods total actually paid.
+func lineRefundAmount(paymentRow database.Payment, lines []database.GetOrderLinesRow, line database.GetOrderLinesRow, quantity int32) int {
+	var subtotal int64
+	for _, l := range lines {
+		subtotal += int64(l.Cost)
+	}
+	if subtotal == 0 {
+		return 0
+	}
+
+	goods := int64(paymentRow.Amount + paymentRow.StoreCredit - paymentRow.DeliveryFee)
+
+	return int(int64(line.Cost) * int64(quantity) * goods / (int64(line.Quantity) * subtotal))
+}
+
+// refundPayment refunds a captured payment, either an amount of the whole order or a set of
+// its lines. A line refund without an amount is priced from the order lines.
+// The provider gives back what it charged first, the rest of the refund returns as store credit.
+//
+// The refund is reserved as pending and committed before the provider is called, so no transaction
+// stays open during the call and money never leaves without a refund row. Restock, store credit and
+// the loyalty point reversal are applied once the provider accepted the refund. A refund the provider
+// rejects is marked failed and changes nothing else.
+func (cfg *ApiConfig) refundPayment(ctx context.Context, adminID, paymentID uuid.UUID, req RefundRequest) (database.Refund, error) {
+	refund, paid, err := cfg.reserveRefund(ctx, paymentID, req)
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	providerRefund := payment.Refund{Status: payment.StatusSucceeded}
+	if providerPart := refund.Amount - refund.StoreCredit; providerPart > 0 {
+		providerRefund, err = cfg.Payments.Refund(ctx, paid.ProviderIntentID.String, int(providerPart))
+		if err != nil {
+			_, failErr := cfg.Queries.SetRefundProvider(ctx, database.SetRefundProviderParams{
+				ID:     refund.ID,
+				Status: payment.StatusFailed,
+			})
+			logger.Warn(failErr, "problem with marking refund as failed")
+			return database.Refund{}, err
+		}
+	}
+
+	settled, err := cfg.settleRefund(ctx, adminID, paid, refund, req.Lines, providerRefund)
+	if err != nil {
+		// The provider already refunded the money, the pending row is left for an admin to settle.
+		logger.Warn(err, "problem with settling refund", refund.ID.String())
+		return database.Refund{}, err
+	}
+
+	return settled, nil
+}
+
+// reserveRefund checks a refund against what is left of the payment and writes it as pending.
+// The payment row is locked until the refund is written, so concurrent refunds can not exceed it.
+func (cfg *ApiConfig) reserveRefund(ctx context.Context, paymentID uuid.UUID, req RefundRequest) (database.Refund, database.Payment, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	lockedPayment, err := qtx.LockPayment(ctx, paymentID)
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+	if lockedPayment.Status != payment.StatusSucceeded {
+		return database.Refund{}, database.Payment{}, errPaymentNotCaptured
+	}
+
+	refunded, err := qtx.GetRefundedAmount(ctx, paymentID)
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+
+	providerRefunded, err := qtx.GetProviderRefundedAmount(ctx, paymentID)
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+
+	lines, err := qtx.GetOrderLines(ctx, paymentID)
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+
+	orderLines := map[uuid.UUID]*database.GetOrderLinesRow{}
+	for i := range lines {
+		orderLines[lines[i].ItemID] = &lines[i]
+	}
+
+	linesAmount := 0
+	for _, refundLine := range req.Lines {
+		line, ok := orderLines[refundLine.ItemID]
+		if !ok || int32(refundLine.Quantity) > line.Quantity-line.RefundedQuantity {
+			return database.Refund{}, database.Payment{}, errRefundLineTooLarge
+		}
+
+		linesAmount += lineRefundAmount(lockedPayment, lines, *line, int32(refundLine.Quantity))
+		line.RefundedQuantity += int32(refundLine.Quantity)
+	}
+
+	// The customer paid with the provider and with store credit, both can be refunded.
+	refundable := lockedPayment.Amount + lockedPayment.StoreCredit - refunded
+
+	amount := int32(req.Amount)
+	if amount == 0 && len(req.Lines) > 0 {
+		amount = int32(linesAmount)
+	}
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount <= 0 || amount > refundable {
+		return database.Refund{}, database.Payment{}, errRefundTooLarge
+	}
+
+	providerPart := min(amount, lockedPayment.Amount-providerRefunded)
+
+	refund, err := qtx.CreateRefund(ctx, database.CreateRefundParams{
+		PaymentID:   paymentID,
+		Amount:      amount,
+		StoreCredit: amount - providerPart,
+		Status:      refundPending,
+		Reason:      req.Reason,
+	})
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+
+	for _, refundLine := range req.Lines {
+		err = qtx.AddRefundItem(ctx, database.AddRefundItemParams{
+			RefundID:  refund.ID,
+			ItemID:    refundLine.ItemID,
+			Quantity:  int32(refundLine.Quantity),
+			Restocked: refundLine.Restock,
+		})
+		if err != nil {
+			return database.Refund{}, database.Payment{}, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return database.Refund{}, database.Payment{}, err
+	}
+
+	return refund, lockedPayment, nil
+}
+
+// settleRefund applies a refund the provider accepted: the store credit part goes back to the
+// customer, returned lines are restocked and the points and referral reward earned on the
+// refunded part are taken back.
+func (cfg *ApiConfig) settleRefund(ctx context.Context, adminID uuid.UUID, paid database.Payment, refund database.Refund, lines []RefundLine, providerRefund payment.Refund) (database.Refund, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.Refund{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	_, err = qtx.LockPayment(ctx, paid.ID)
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	reference := "refund:" + refund.ID.String()
+
+	if refund.StoreCredit > 0 {
+		err = addStoreCredit(ctx, qtx, paid.UserID, refund.StoreCredit, creditMovement{
+			Reason:    creditReasonRefund,
+			ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+			Reference: reference,
+		})
+		if err != nil {
+			return database.Refund{}, err
+		}
+	}
+
+	// Points are earned on what the provider charged, so only its part of the refund takes them back.
+	err = reversePaymentPoints(ctx, qtx, paid, refund.ID, refund.Amount-refund.StoreCredit)
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	refunded, err := qtx.GetRefundedAmount(ctx, paid.ID)
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	err = reverseReferral(ctx, qtx, paid, refunded)
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	backInStock := map[uuid.UUID]string{}
+	for _, refundLine := range lines {
+		if !refundLine.Restock {
+			continue
+		}
+
+		items, err := returnStock(ctx, qtx, refundLine.ItemID, int32(refundLine.Quantity), nil, stockMovement{
+			Reason:    stockReasonRefund,
+			ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+			Reference: reference,
+		})
+		if err != nil {
+			return database.Refund{}, err
+		}
+
+		for _, item := range items {
+			backInStock[item.ID] = item.Name
+		}
+	}
+
+	refund, err = qtx.SetRefundProvider(ctx, database.SetRefundProviderParams{
+		ID:               refund.ID,
+		ProviderRefundID: sql.NullString{String: providerRefund.ID, Valid: providerRefund.ID != ""},
+		Status:           providerRefund.Status,
+	})
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return database.Refund{}, err
+	}
+
+	for itemID, name := range backInStock {
+		go cfg.notifyBackInStock(itemID, name)
+	}
+
+	return refund, nil
+}
+
+func (cfg *ApiConfig) HandlerRefundPayment(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	paymentID, err := uuid.Parse(r.PathValue("paymentID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	refundRequest := RefundRequest{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&refundRequest)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	for _, refundLine := range refundRequest.Lines {
+		if refundLine.Quantity <= 0 {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	refund, err := cfg.refundPayment(context.Background(), adminID, paymentID, refundRequest)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Not found"}`, http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, errPaymentNotCaptured) {
+		http.Error(w, `{"error": "Payment is not captured"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, errRefundTooLarge) {
+		http.Error(w, `{"error": "Refund exceeds payment amount"}`, http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, errRefundLineTooLarge) {
+		http.Error(w, `{"error": "Refund exceeds what the payment bought"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with refunding payment"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(refund)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
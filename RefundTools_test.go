@@ -0,0 +1,54 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLineRefundAmount(t *testing.T) {
+	lines := []database.GetOrderLinesRow{
+		{ItemID: uuid.New(), Quantity: 4, Cost: 400},
+		{ItemID: uuid.New(), Quantity: 1, Cost: 600},
+	}
+
+	tests := []struct {
+		name     string
+		payment  database.Payment
+		line     database.GetOrderLinesRow
+		quantity int32
+		want     int
+	}{
+		{
+			name:     "full price",
+			payment:  database.Payment{Amount: 1000},
+			line:     lines[0],
+			quantity: 2,
+			want:     200,
+		},
+		{
+			name:     "delivery fee is not refunded",
+			payment:  database.Payment{Amount: 1150, DeliveryFee: 150},
+			line:     lines[1],
+			quantity: 1,
+			want:     600,
+		},
+		{
+			name:     "discount is spread over the lines",
+			payment:  database.Payment{Amount: 500, StoreCredit: 300},
+			line:     lines[0],
+			quantity: 4,
+			want:     320,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lineRefundAmount(tt.payment, lines, tt.line, tt.quantity)
+			if got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/logger"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// searchConfig picks the text search configuration used to highlight matches,
+// a query written in Cyrillic is treated as Russian and everything else as English.
+func searchConfig(query string) string {
+	for _, r := range query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return "russian"
+		}
+	}
+
+	return "english"
+}
+
+func (cfg *ApiConfig) HandlerSearchItems(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultSearchLimit
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		var err error
+		limit, err = strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+	limit = min(limit, maxSearchLimit)
+
+	results, err := cfg.Queries.SearchItems(context.Background(), database.SearchItemsParams{
+		Query:      query,
+		Config:     searchConfig(query),
+		MaxResults: int32(limit),
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	if results == nil {
+		results = []database.SearchItemsRow{}
+	}
+
+	respData, err := json.Marshal(results)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -5,8 +5,11 @@ import (
 	"HomeFruits/internal/jwt"
 	"HomeFruits/logger"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,6 +22,12 @@ type GetItemParams struct {
 	Cost     int
 }
 
+type ItemWithImages struct {
+	database.GetAllItemsRow
+	Season
+	Images []ImageURLs `json:"images"`
+}
+
 func (cfg *ApiConfig) HandlerGetItems(w http.ResponseWriter, r *http.Request) {
 	items, err := cfg.Queries.GetAllItems(context.Background())
 	if err != nil {
@@ -27,7 +36,38 @@ func (cfg *ApiConfig) HandlerGetItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respData, err := json.Marshal(items)
+	urls, err := cfg.itemImageURLs(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	windows, err := cfg.itemSeasons(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	// ?in_season=true leaves out the items that can not be bought right now.
+	onlyInSeason := r.URL.Query().Get("in_season") == "true"
+
+	itemsWithImages := []ItemWithImages{}
+	for _, item := range items {
+		season := seasonOf(windows[item.ID], time.Now())
+		if onlyInSeason && !season.InSeason {
+			continue
+		}
+
+		itemsWithImages = append(itemsWithImages, ItemWithImages{
+			GetAllItemsRow: item,
+			Season:         season,
+			Images:         append([]ImageURLs{}, urls[item.ID]...),
+		})
+	}
+
+	respData, err := json.Marshal(itemsWithImages)
 	if err != nil {
 		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
 		logger.Warn(err)
@@ -99,6 +139,18 @@ func (cfg *ApiConfig) HandlerGetInCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	windows, err := cfg.Queries.GetItemSeasons(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if !seasonOf(windows, time.Now()).InSeason {
+		http.Error(w, `{"error": "Item is out of season"}`, http.StatusConflict)
+		return
+	}
+
 	newItemInCart := GetItemParams{
 		UserID: userID,
 		ItemID: itemID,
@@ -115,7 +167,7 @@ func (cfg *ApiConfig) HandlerGetInCart(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if newItemInCart.Quantity > int(item.Quantity) {
+	if newItemInCart.Quantity <= 0 || newItemInCart.Quantity > int(item.Quantity) {
 		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
 		return
 	}
@@ -128,25 +180,46 @@ func (cfg *ApiConfig) HandlerGetInCart(w http.ResponseWriter, r *http.Request) {
 		ItemName: newItemInCart.Name,
 	}
 
-	err = cfg.Queries.AddItemInCart(context.Background(), args)
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	err = reserveCartStock(context.Background(), qtx, userID, itemID, int32(newItemInCart.Quantity), stockMovement{
+		Reason:  stockReasonCartAdd,
+		ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if errors.Is(err, errOutOfStock) {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
 		return
 	}
 
-	updateArgs := database.UpdateItemQuantityParams{
-		Quantity: item.Quantity - int32(newItemInCart.Quantity),
-		ID:       itemID,
+	err = qtx.AddItemInCart(context.Background(), args)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
 	}
 
-	err = cfg.Queries.UpdateItemQuantity(context.Background(), updateArgs)
+	err = tx.Commit()
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
 		return
 	}
 
+	go cfg.checkLowStock(itemID)
+
 	w.WriteHeader(http.StatusCreated)
 }
 
@@ -176,23 +249,41 @@ func (cfg *ApiConfig) HandlerDeleteFromCart(w http.ResponseWriter, r *http.Reque
 		ItemID: itemID,
 		UserID: userID,
 	}
-	deletedItem, err := cfg.Queries.DeleteFromCart(context.Background(), args)
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	deletedItem, err := qtx.DeleteFromCart(context.Background(), args)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found in shopping cart"}`, http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
 		return
 	}
 
-	realItem, err := cfg.Queries.GetItemById(context.Background(), deletedItem.ItemID)
+	restocked, err := releaseCartStock(context.Background(), qtx, userID, deletedItem.ItemID, deletedItem.Quantity, stockMovement{
+		Reason:  stockReasonCartRemove,
+		ActorID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
 		return
 	}
 
-	err = cfg.Queries.UpdateItemQuantity(context.Background(), database.UpdateItemQuantityParams{
-		Quantity: realItem.Quantity + deletedItem.Quantity,
-		ID: deletedItem.ItemID,
+	err = qtx.DeleteBoxReservation(context.Background(), database.DeleteBoxReservationParams{
+		UserID: userID,
+		ItemID: itemID,
 	})
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
@@ -200,5 +291,16 @@ func (cfg *ApiConfig) HandlerDeleteFromCart(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	for _, item := range restocked {
+		go cfg.notifyBackInStock(item.ID, item.Name)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
\ No newline at end of file
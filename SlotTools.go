@@ -0,0 +1,439 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/delivery"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	dayLayout  = "2006-01-02"
+	timeLayout = "15:04"
+)
+
+var errSlotOutsideZone = errors.New("delivery slot does not serve the address")
+
+type NewDeliverySlot struct {
+	ZoneID   uuid.UUID `json:"zone_id"`
+	Day      string    `json:"day"`
+	StartsAt string    `json:"starts_at"`
+	EndsAt   string    `json:"ends_at"`
+	Capacity int       `json:"capacity"`
+}
+
+type DeliverySlot struct {
+	ID        uuid.UUID `json:"id"`
+	ZoneID    uuid.UUID `json:"zone_id"`
+	Day       string    `json:"day"`
+	StartsAt  string    `json:"starts_at"`
+	EndsAt    string    `json:"ends_at"`
+	Capacity  int       `json:"capacity"`
+	Available int       `json:"available"`
+}
+
+func slotFromDB(s database.DeliverySlot) DeliverySlot {
+	return DeliverySlot{
+		ID:        s.ID,
+		ZoneID:    s.ZoneID,
+		Day:       s.Day.Format(dayLayout),
+		StartsAt:  s.StartsAt.Format(timeLayout),
+		EndsAt:    s.EndsAt.Format(timeLayout),
+		Capacity:  int(s.Capacity),
+		Available: int(s.Capacity - s.Booked),
+	}
+}
+
+// holdCartSlot books slotID for the user's cart and gives back any slot held before.
+// Both happen in one transaction, so a full slot never gets overbooked and a failed
+// booking keeps the old one. The slot has to belong to zoneID, the zone of the delivery address.
+func (cfg *ApiConfig) holdCartSlot(ctx context.Context, userID, slotID, zoneID uuid.UUID) (database.DeliverySlot, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.DeliverySlot{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	oldSlotID, err := qtx.DeleteCartSlot(ctx, userID)
+	if err == nil {
+		err = qtx.ReleaseDeliverySlot(ctx, oldSlotID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.DeliverySlot{}, err
+	}
+
+	slot, err := qtx.ReserveDeliverySlot(ctx, slotID)
+	if err != nil {
+		return database.DeliverySlot{}, err
+	}
+	if slot.ZoneID != zoneID {
+		return database.DeliverySlot{}, errSlotOutsideZone
+	}
+
+	err = qtx.SetCartSlot(ctx, database.SetCartSlotParams{
+		UserID: userID,
+		SlotID: slotID,
+	})
+	if err != nil {
+		return database.DeliverySlot{}, err
+	}
+
+	return slot, tx.Commit()
+}
+
+func (cfg *ApiConfig) releaseCartSlot(ctx context.Context, userID uuid.UUID) error {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	slotID, err := qtx.DeleteCartSlot(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = qtx.ReleaseDeliverySlot(ctx, slotID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// bookPaymentSlot turns the slot a succeeded payment took from the cart into a delivery booking.
+func bookPaymentSlot(ctx context.Context, qtx *database.Queries, paid database.Payment) error {
+	if !paid.SlotID.Valid {
+		return nil
+	}
+
+	return qtx.CreateDeliveryBooking(ctx, database.CreateDeliveryBookingParams{
+		PaymentID: paid.ID,
+		SlotID:    paid.SlotID.UUID,
+		UserID:    paid.UserID,
+		AddressID: paid.AddressID,
+	})
+}
+
+// releasePaymentSlot frees the capacity held by a payment that did not go through.
+func releasePaymentSlot(ctx context.Context, qtx *database.Queries, paymentRow database.Payment) error {
+	if !paymentRow.SlotID.Valid {
+		return nil
+	}
+
+	return qtx.ReleaseDeliverySlot(ctx, paymentRow.SlotID.UUID)
+}
+
+func (cfg *ApiConfig) HandlerGetDeliverySlots(w http.ResponseWriter, r *http.Request) {
+	zoneID, err := uuid.Parse(r.URL.Query().Get("zone_id"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	day := time.Now()
+	if rawDay := r.URL.Query().Get("date"); rawDay != "" {
+		day, err = time.Parse(dayLayout, rawDay)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	slots, err := cfg.Queries.GetDeliverySlots(context.Background(), database.GetDeliverySlotsParams{
+		ZoneID: zoneID,
+		Day:    day,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	resp := make([]DeliverySlot, 0, len(slots))
+	for _, slot := range slots {
+		resp = append(resp, slotFromDB(slot))
+	}
+
+	respData, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetCartSlot(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	slot, err := cfg.Queries.GetCartSlot(context.Background(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "No delivery slot selected"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(slotFromDB(slot))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerHoldCartSlot(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	slotID, err := uuid.Parse(r.PathValue("slotID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	addressID, err := uuid.Parse(r.URL.Query().Get("address_id"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	address, err := cfg.Queries.GetUserAddress(context.Background(), database.GetUserAddressParams{
+		ID:     addressID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Address not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	zone, err := cfg.deliveryZoneFor(context.Background(), address)
+	if errors.Is(err, delivery.ErrOutsideZones) {
+		http.Error(w, `{"error": "Address is outside of delivery zones"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	slot, err := cfg.holdCartSlot(context.Background(), userID, slotID, zone.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Delivery slot is not available"}`, http.StatusConflict)
+		return
+	}
+	if errors.Is(err, errSlotOutsideZone) {
+		http.Error(w, `{"error": "Delivery slot does not serve the address"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(slotFromDB(slot))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerReleaseCartSlot(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.releaseCartSlot(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *ApiConfig) HandlerCreateDeliverySlot(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	newSlot := NewDeliverySlot{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newSlot)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	day, errDay := time.Parse(dayLayout, newSlot.Day)
+	startsAt, errStart := time.Parse(timeLayout, newSlot.StartsAt)
+	endsAt, errEnd := time.Parse(timeLayout, newSlot.EndsAt)
+	err = errors.Join(errDay, errStart, errEnd)
+	if err != nil || !endsAt.After(startsAt) || newSlot.Capacity <= 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	createdSlot, err := cfg.Queries.CreateDeliverySlot(context.Background(), database.CreateDeliverySlotParams{
+		ZoneID:   newSlot.ZoneID,
+		Day:      day,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Capacity: int32(newSlot.Capacity),
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(slotFromDB(createdSlot))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerDeleteDeliverySlot(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		return
+	}
+
+	slotID, err := uuid.Parse(r.PathValue("slotID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	err = cfg.Queries.DeleteDeliverySlot(context.Background(), slotID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
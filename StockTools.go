@@ -0,0 +1,419 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	stockReasonOpeningBalance = "opening_balance"
+	stockReasonRestock        = "restock"
+	stockReasonCartAdd        = "cart_add"
+	stockReasonCartRemove     = "cart_remove"
+	stockReasonRefund         = "refund_restock"
+	stockReasonPaymentFailed  = "payment_failed"
+	stockReasonWriteOff       = "write_off"
+	stockReasonImport         = "import"
+)
+
+var (
+	errOutOfStock      = errors.New("not enough items in stock")
+	errInvalidQuantity = errors.New("quantity must be positive")
+)
+
+// stockMovement tells the ledger why stock changed, who changed it and what it belongs to.
+type stockMovement struct {
+	Reason    string
+	ActorID   uuid.NullUUID
+	Reference string
+}
+
+type ItemMovements struct {
+	ItemID         uuid.UUID                `json:"item_id"`
+	Quantity       int32                    `json:"quantity"`
+	LedgerQuantity int32                    `json:"ledger_quantity"`
+	Movements      []database.StockMovement `json:"movements"`
+}
+
+// batchAllocation is a part of a sale taken from one inventory batch.
+type batchAllocation struct {
+	BatchID  uuid.UUID
+	Quantity int32
+}
+
+// restockedItem is an item that came back in stock and has to be announced to its subscribers.
+type restockedItem struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// recordStockMovement appends a stock change to the ledger. Every change of items.quantity
+// has to go through it, so the quantity can always be rebuilt from the ledger.
+// Stock growing back above its reorder threshold re-arms the low stock alert.
+func recordStockMovement(ctx context.Context, qtx *database.Queries, itemID uuid.UUID, delta int32, movement stockMovement) error {
+	err := qtx.InsertStockMovement(ctx, database.InsertStockMovementParams{
+		ItemID:    itemID,
+		Delta:     delta,
+		Reason:    movement.Reason,
+		ActorID:   movement.ActorID,
+		Reference: movement.Reference,
+	})
+	if err != nil {
+		return err
+	}
+
+	if delta > 0 {
+		return qtx.ClearLowStockAlert(ctx, itemID)
+	}
+
+	return nil
+}
+
+// takeStock removes quantity units of an item from stock and reports the batches they came from.
+// A bundle has no stock of its own, so its components are taken instead.
+func takeStock(ctx context.Context, qtx *database.Queries, itemID uuid.UUID, quantity int32, movement stockMovement) ([]batchAllocation, error) {
+	if quantity <= 0 {
+		return nil, errInvalidQuantity
+	}
+
+	components, err := qtx.GetBundleComponents(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		components = []database.GetBundleComponentsRow{{ItemID: itemID, Quantity: 1}}
+	}
+
+	allocations := []batchAllocation{}
+	for _, component := range components {
+		taken, err := takeBatches(ctx, qtx, component.ItemID, component.Quantity*quantity)
+		if errors.Is(err, errOutOfStock) {
+			// Give back the components taken so far, the caller may keep using the transaction.
+			_, putBackErr := putBackBatches(ctx, qtx, allocations, nil)
+			if putBackErr != nil {
+				return nil, putBackErr
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		allocations = append(allocations, taken...)
+	}
+
+	for _, component := range components {
+		err = recordStockMovement(ctx, qtx, component.ItemID, -component.Quantity*quantity, movement)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, nil
+}
+
+// takeBatches takes amount units of a plain item, oldest non-expired batch first.
+// The caller records the movement once the whole sale is known to fit in stock.
+func takeBatches(ctx context.Context, qtx *database.Queries, itemID uuid.UUID, amount int32) ([]batchAllocation, error) {
+	taken, err := qtx.TakeItemStock(ctx, database.TakeItemStockParams{
+		Amount: amount,
+		ID:     itemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if taken == 0 {
+		return nil, errOutOfStock
+	}
+
+	allocations := []batchAllocation{}
+	for remaining := amount; remaining > 0; {
+		batch, err := qtx.GetOldestBatch(ctx, itemID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The rest of the stock has expired and waits for the write-off job.
+			_, err = qtx.AddItemStock(ctx, database.AddItemStockParams{
+				Amount: remaining,
+				ID:     itemID,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = putBackBatches(ctx, qtx, allocations, nil)
+			if err != nil {
+				return nil, err
+			}
+			return nil, errOutOfStock
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		allocation := batchAllocation{BatchID: batch.ID, Quantity: min(remaining, batch.Quantity)}
+		err = qtx.TakeBatchStock(ctx, database.TakeBatchStockParams{
+			Amount: allocation.Quantity,
+			ID:     allocation.BatchID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		allocations = append(allocations, allocation)
+		remaining -= allocation.Quantity
+	}
+
+	return allocations, nil
+}
+
+// returnStock puts quantity units of an item back in stock and reports the items
+// that went from zero to positive stock on the way, the bundle itself included.
+// Units go back to the batches in allocations; without them they join the newest batch.
+func returnStock(ctx context.Context, qtx *database.Queries, itemID uuid.UUID, quantity int32, allocations []batchAllocation, movement stockMovement) ([]restockedItem, error) {
+	components, err := qtx.GetBundleComponents(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	isBundle := len(components) > 0
+	if !isBundle {
+		components = []database.GetBundleComponentsRow{{ItemID: itemID, Quantity: 1}}
+	}
+
+	var bundleStock int32
+	if isBundle {
+		bundleStock, err = qtx.GetBundleStock(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(allocations) == 0 {
+		for _, component := range components {
+			batch, err := newestBatch(ctx, qtx, component.ItemID)
+			if err != nil {
+				return nil, err
+			}
+
+			allocations = append(allocations, batchAllocation{
+				BatchID:  batch.ID,
+				Quantity: component.Quantity * quantity,
+			})
+		}
+	}
+
+	restocked, err := putBackBatches(ctx, qtx, allocations, &movement)
+	if err != nil {
+		return nil, err
+	}
+
+	if isBundle && bundleStock == 0 {
+		bundle, err := qtx.GetItemById(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+		if bundle.Quantity > 0 {
+			restocked = append(restocked, restockedItem{ID: itemID, Name: bundle.Name})
+		}
+	}
+
+	return restocked, nil
+}
+
+// putBackBatches returns allocated units to their batches. Units of a batch written off
+// in the meantime are spoiled, so they do not count towards the item stock.
+// A nil movement undoes a take that was never recorded in the ledger.
+func putBackBatches(ctx context.Context, qtx *database.Queries, allocations []batchAllocation, movement *stockMovement) ([]restockedItem, error) {
+	restocked := []restockedItem{}
+	for _, allocation := range allocations {
+		batch, err := qtx.AddBatchStock(ctx, database.AddBatchStockParams{
+			Amount: allocation.Quantity,
+			ID:     allocation.BatchID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if batch.WrittenOffAt.Valid {
+			continue
+		}
+
+		item, err := qtx.AddItemStock(ctx, database.AddItemStockParams{
+			Amount: allocation.Quantity,
+			ID:     batch.ItemID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if movement != nil {
+			err = recordStockMovement(ctx, qtx, batch.ItemID, allocation.Quantity, *movement)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if item.Quantity == allocation.Quantity && allocation.Quantity > 0 {
+			restocked = append(restocked, restockedItem{ID: batch.ItemID, Name: item.Name})
+		}
+	}
+
+	return restocked, nil
+}
+
+// newestBatch finds the freshest batch of an item, starting an empty one if none is left.
+func newestBatch(ctx context.Context, qtx *database.Queries, itemID uuid.UUID) (database.ItemBatch, error) {
+	batch, err := qtx.GetNewestBatch(ctx, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return qtx.CreateItemBatch(ctx, database.CreateItemBatchParams{
+			ItemID:     itemID,
+			ReceivedAt: time.Now(),
+		})
+	}
+
+	return batch, err
+}
+
+// reserveCartStock takes stock for a shopping cart line and remembers its batches,
+// so that removing the line puts the units back where they came from.
+func reserveCartStock(ctx context.Context, qtx *database.Queries, userID, itemID uuid.UUID, quantity int32, movement stockMovement) error {
+	allocations, err := takeStock(ctx, qtx, itemID, quantity, movement)
+	if err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		err = qtx.AddCartBatchAllocation(ctx, database.AddCartBatchAllocationParams{
+			UserID:   userID,
+			ItemID:   itemID,
+			BatchID:  allocation.BatchID,
+			Quantity: allocation.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseCartStock puts the stock of a removed shopping cart line back.
+func releaseCartStock(ctx context.Context, qtx *database.Queries, userID, itemID uuid.UUID, quantity int32, movement stockMovement) ([]restockedItem, error) {
+	rows, err := qtx.PopCartBatchAllocations(ctx, database.PopCartBatchAllocationsParams{
+		UserID: userID,
+		ItemID: itemID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := []batchAllocation{}
+	for _, row := range rows {
+		allocations = append(allocations, batchAllocation{BatchID: row.BatchID, Quantity: row.Quantity})
+	}
+
+	return returnStock(ctx, qtx, itemID, quantity, allocations, movement)
+}
+
+// returnPaymentStock puts the items of a payment that did not go through back into stock.
+// The cart they were reserved in is gone by then, so the units join the newest batches.
+func returnPaymentStock(ctx context.Context, qtx *database.Queries, paymentRow database.Payment) error {
+	lines, err := qtx.GetPaymentItems(ctx, paymentRow.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		_, err = returnStock(ctx, qtx, line.ItemID, line.Quantity, nil, stockMovement{
+			Reason:    stockReasonPaymentFailed,
+			ActorID:   uuid.NullUUID{UUID: paymentRow.UserID, Valid: true},
+			Reference: "payment:" + paymentRow.ID.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *ApiConfig) HandlerGetItemMovements(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	itemID, err := uuid.Parse(r.PathValue("itemID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	item, err := cfg.Queries.GetItemById(context.Background(), itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	ledgerQuantity, err := cfg.Queries.GetLedgerQuantity(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	movements, err := cfg.Queries.GetItemMovements(context.Background(), itemID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(ItemMovements{
+		ItemID:         itemID,
+		Quantity:       item.Quantity,
+		LedgerQuantity: ledgerQuantity,
+		Movements:      movements,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestDeleteUserKeepsStockMovements(t *testing.T) {
+	cfg := newTestConfig(t)
+	ctx := context.Background()
+
+	user, token := newTestUser(t, cfg)
+	item := newTestItem(t, cfg, 5)
+
+	w := serveTest(t, cfg.HandlerGetInCart, http.MethodPost, "/api/item/"+item.ID.String(), token, map[string]int{"quantity": 2}, map[string]string{"itemID": item.ID.String()})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("adding to cart: got status %d: %s", w.Code, w.Body)
+	}
+
+	_, err := cfg.DB.ExecContext(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	if err != nil {
+		t.Fatalf("deleting a user with stock movements: %v", err)
+	}
+
+	movements, err := cfg.Queries.GetItemMovements(ctx, item.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movements) != 1 {
+		t.Fatalf("got %d movements, want the cart movement to stay", len(movements))
+	}
+	if movements[0].Delta != -2 || movements[0].Reason != stockReasonCartAdd {
+		t.Fatalf("movement changed: got %+v", movements[0])
+	}
+	if movements[0].ActorID.Valid {
+		t.Fatalf("actor of a deleted user is still set: %v", movements[0].ActorID.UUID)
+	}
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/notifier"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	subscriptionActive    = "active"
+	subscriptionPaused    = "paused"
+	subscriptionCancelled = "cancelled"
+)
+
+// boxHoldTime is how long a filled box keeps its stock in the shopping cart waiting for payment.
+const boxHoldTime = 48 * time.Hour
+
+type NewSubscription struct {
+	Cadence  string             `json:"cadence"`
+	StartsAt *time.Time         `json:"starts_at"`
+	Items    []SubscriptionItem `json:"items"`
+}
+
+type SubscriptionItem struct {
+	ItemID   uuid.UUID `json:"item_id"`
+	Quantity int       `json:"quantity"`
+}
+
+type SubscriptionWithItems struct {
+	database.Subscription
+	Items []database.GetSubscriptionItemsRow `json:"items"`
+}
+
+func nextRun(cadence string, from time.Time) time.Time {
+	switch cadence {
+	case "weekly":
+		return from.AddDate(0, 0, 7)
+	case "biweekly":
+		return from.AddDate(0, 0, 14)
+	default:
+		return from.AddDate(0, 1, 0)
+	}
+}
+
+func validCadence(cadence string) bool {
+	return cadence == "weekly" || cadence == "biweekly" || cadence == "monthly"
+}
+
+// runSubscriptions fills the box of every due subscription and releases the boxes left unpaid
+// each interval until the process exits.
+func (cfg *ApiConfig) runSubscriptions(interval time.Duration) {
+	for range time.Tick(interval) {
+		for {
+			processed, err := cfg.processDueSubscription(context.Background())
+			if err != nil {
+				logger.Warn(err, "problem with processing subscription")
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		for {
+			released, err := cfg.releaseUnpaidBoxLine(context.Background())
+			if err != nil {
+				logger.Warn(err, "problem with releasing unpaid box")
+				break
+			}
+			if !released {
+				break
+			}
+		}
+	}
+}
+
+// processDueSubscription puts the box of one due subscription into the user's shopping cart,
+// where it is held for boxHoldTime. Items without enough stock are replaced by the closest priced item of the same category,
+// or left out if there is none; the user is told about both. It reports false when nothing is due.
+func (cfg *ApiConfig) processDueSubscription(ctx context.Context) (bool, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	sub, err := qtx.ClaimDueSubscription(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	next := nextRun(sub.Cadence, sub.NextRunAt)
+	for !next.After(time.Now()) {
+		next = nextRun(sub.Cadence, next)
+	}
+
+	changes := []string{}
+	taken := []uuid.UUID{}
+	if !sub.SkipNext {
+		boxItems, err := qtx.GetSubscriptionItems(ctx, sub.ID)
+		if err != nil {
+			return false, err
+		}
+
+		movement := stockMovement{
+			Reason:    stockReasonCartAdd,
+			ActorID:   uuid.NullUUID{UUID: sub.UserID, Valid: true},
+			Reference: "subscription:" + sub.ID.String(),
+		}
+
+		for _, boxItem := range boxItems {
+			windows, err := qtx.GetItemSeasons(ctx, boxItem.ItemID)
+			if err != nil {
+				return false, err
+			}
+
+			err = errOutOfSeason
+			if seasonOf(windows, time.Now()).InSeason {
+				err = reserveCartStock(ctx, qtx, sub.UserID, boxItem.ItemID, boxItem.Quantity, movement)
+			}
+			if err != nil && !errors.Is(err, errOutOfStock) && !errors.Is(err, errOutOfSeason) {
+				return false, err
+			}
+
+			unavailable := "out of stock"
+			if errors.Is(err, errOutOfSeason) {
+				unavailable = "out of season"
+			}
+
+			itemID, name, cost := boxItem.ItemID, boxItem.Name, boxItem.Cost
+			if err != nil {
+				substitute, err := qtx.GetSubstituteItem(ctx, database.GetSubstituteItemParams{
+					Category:   boxItem.Category,
+					ID:         boxItem.ItemID,
+					Amount:     boxItem.Quantity,
+					TargetCost: boxItem.Cost,
+				})
+				if err == nil {
+					err = reserveCartStock(ctx, qtx, sub.UserID, substitute.ID, boxItem.Quantity, movement)
+				}
+				if err != nil && !errors.Is(err, sql.ErrNoRows) && !errors.Is(err, errOutOfStock) {
+					return false, err
+				}
+
+				if err != nil {
+					changes = append(changes, fmt.Sprintf("%s is %s and was left out", boxItem.Name, unavailable))
+					continue
+				}
+
+				itemID, name, cost = substitute.ID, substitute.Name, substitute.Cost
+				changes = append(changes, fmt.Sprintf("%s was replaced with %s", boxItem.Name, substitute.Name))
+			}
+
+			err = qtx.AddItemInCart(ctx, database.AddItemInCartParams{
+				ItemID:   itemID,
+				UserID:   sub.UserID,
+				Quantity: boxItem.Quantity,
+				Cost:     cost * boxItem.Quantity,
+				ItemName: name,
+			})
+			if err != nil {
+				return false, err
+			}
+
+			err = qtx.ReserveBoxLine(ctx, database.ReserveBoxLineParams{
+				UserID:         sub.UserID,
+				ItemID:         itemID,
+				SubscriptionID: sub.ID,
+				ReleaseAt:      time.Now().Add(boxHoldTime),
+			})
+			if err != nil {
+				return false, err
+			}
+
+			taken = append(taken, itemID)
+		}
+	}
+
+	err = qtx.AdvanceSubscription(ctx, database.AdvanceSubscriptionParams{
+		ID:        sub.ID,
+		NextRunAt: next,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	if len(changes) > 0 {
+		go cfg.notifySubscriptionChanges(sub.UserID, changes)
+	}
+	go cfg.checkLowStock(taken...)
+
+	return true, nil
+}
+
+// releaseUnpaidBoxLine takes one box line that was not paid within boxHoldTime out of the shopping
+// cart and puts its stock back on sale. It reports false when nothing is left to release.
+func (cfg *ApiConfig) releaseUnpaidBoxLine(ctx context.Context) (bool, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	reservation, err := qtx.ClaimExpiredBoxReservation(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	deletedItem, err := qtx.DeleteFromCart(ctx, database.DeleteFromCartParams{
+		ItemID: reservation.ItemID,
+		UserID: reservation.UserID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, tx.Commit()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	restocked, err := releaseCartStock(ctx, qtx, reservation.UserID, deletedItem.ItemID, deletedItem.Quantity, stockMovement{
+		Reason:    stockReasonCartRemove,
+		Reference: "subscription:" + reservation.SubscriptionID.String(),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+
+	go cfg.notifySubscriptionChanges(reservation.UserID, []string{
+		fmt.Sprintf("%s was not paid in time and was taken out of your cart", deletedItem.ItemName),
+	})
+	for _, item := range restocked {
+		go cfg.notifyBackInStock(item.ID, item.Name)
+	}
+
+	return true, nil
+}
+
+func (cfg *ApiConfig) notifySubscriptionChanges(userID uuid.UUID, changes []string) {
+	email, err := cfg.Queries.GetUserEmail(context.Background(), userID)
+	if err != nil {
+		logger.Warn(err, "problem with loading subscriber email")
+		return
+	}
+
+	err = cfg.Notifier.Notify(context.Background(), notifier.Notification{
+		To:      email,
+		Subject: "Changes in your fruit box",
+		Message: "Your subscription box is in your shopping cart with some changes:\n" + strings.Join(changes, "\n"),
+	})
+	logger.Warn(err, "problem with sending subscription notification")
+}
+
+func (cfg *ApiConfig) HandlerCreateSubscription(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	newSubscription := NewSubscription{}
+
+	decoder := json.NewDecoder(r.Body)
+	err = decoder.Decode(&newSubscription)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if !validCadence(newSubscription.Cadence) || len(newSubscription.Items) == 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+	for _, item := range newSubscription.Items {
+		if item.Quantity <= 0 {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	startsAt := time.Now()
+	if newSubscription.StartsAt != nil {
+		startsAt = *newSubscription.StartsAt
+	}
+
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	subscription, err := qtx.CreateSubscription(context.Background(), database.CreateSubscriptionParams{
+		UserID:    userID,
+		Cadence:   newSubscription.Cadence,
+		NextRunAt: startsAt,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	for _, item := range newSubscription.Items {
+		err = qtx.AddSubscriptionItem(context.Background(), database.AddSubscriptionItemParams{
+			SubscriptionID: subscription.ID,
+			ItemID:         item.ItemID,
+			Quantity:       int32(item.Quantity),
+		})
+		if err != nil {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(subscription)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	subscriptions, err := cfg.Queries.GetUserSubscriptions(context.Background(), userID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	resp := make([]SubscriptionWithItems, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		items, err := cfg.Queries.GetSubscriptionItems(context.Background(), subscription.ID)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+		resp = append(resp, SubscriptionWithItems{
+			Subscription: subscription,
+			Items:        items,
+		})
+	}
+
+	respData, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerPauseSubscription(w http.ResponseWriter, r *http.Request) {
+	cfg.changeSubscriptionStatus(w, r, subscriptionPaused)
+}
+
+func (cfg *ApiConfig) HandlerResumeSubscription(w http.ResponseWriter, r *http.Request) {
+	cfg.changeSubscriptionStatus(w, r, subscriptionActive)
+}
+
+func (cfg *ApiConfig) HandlerCancelSubscription(w http.ResponseWriter, r *http.Request) {
+	cfg.changeSubscriptionStatus(w, r, subscriptionCancelled)
+}
+
+func (cfg *ApiConfig) changeSubscriptionStatus(w http.ResponseWriter, r *http.Request, status string) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(r.PathValue("subscriptionID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	subscription, err := cfg.Queries.SetSubscriptionStatus(context.Background(), database.SetSubscriptionStatusParams{
+		ID:     subscriptionID,
+		UserID: userID,
+		Status: status,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Subscription not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(subscription)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerSkipSubscription(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	userID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	subscriptionID, err := uuid.Parse(r.PathValue("subscriptionID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	subscription, err := cfg.Queries.SkipNextDelivery(context.Background(), database.SkipNextDeliveryParams{
+		ID:     subscriptionID,
+		UserID: userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Subscription not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(subscription)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
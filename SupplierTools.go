@@ -0,0 +1,656 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/logger"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var errPurchaseOrderClosed = errors.New("purchase order is not open")
+
+type Supplier struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+type PurchaseOrderLine struct {
+	ItemID     uuid.UUID `json:"item_id"`
+	Quantity   int       `json:"quantity"`
+	UnitCost   int       `json:"unit_cost"`
+	BestBefore string    `json:"best_before"`
+}
+
+type PurchaseOrder struct {
+	SupplierID uuid.UUID           `json:"supplier_id"`
+	ExpectedAt string              `json:"expected_at"`
+	Lines      []PurchaseOrderLine `json:"lines"`
+}
+
+type PurchaseOrderWithLines struct {
+	database.PurchaseOrder
+	Lines []database.PurchaseOrderLine `json:"lines"`
+}
+
+type ItemMargin struct {
+	database.GetItemMarginsRow
+	Margin        int32   `json:"margin"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
+func (cfg *ApiConfig) HandlerGetSuppliers(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	suppliers, err := cfg.Queries.GetSuppliers(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(suppliers)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreateSupplier(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	newSupplier := Supplier{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&newSupplier)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if newSupplier.Name == "" {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	supplier, err := cfg.Queries.CreateSupplier(context.Background(), database.CreateSupplierParams{
+		Name:  newSupplier.Name,
+		Email: newSupplier.Email,
+		Phone: newSupplier.Phone,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(supplier)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrders, err := cfg.Queries.GetPurchaseOrders(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(purchaseOrders)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerGetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrderID, err := uuid.Parse(r.PathValue("purchaseOrderID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrder, err := cfg.Queries.GetPurchaseOrder(context.Background(), purchaseOrderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Purchase order not found"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	lines, err := cfg.Queries.GetPurchaseOrderLines(context.Background(), purchaseOrderID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(PurchaseOrderWithLines{
+		PurchaseOrder: purchaseOrder,
+		Lines:         lines,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	newPurchaseOrder := PurchaseOrder{}
+
+	decoder := json.NewDecoder(r.Body)
+
+	err = decoder.Decode(&newPurchaseOrder)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with decoding json"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	expectedAt, err := time.Parse(dayLayout, newPurchaseOrder.ExpectedAt)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	if len(newPurchaseOrder.Lines) == 0 {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		return
+	}
+
+	bestBefores := make([]sql.NullTime, len(newPurchaseOrder.Lines))
+	for i, line := range newPurchaseOrder.Lines {
+		if line.Quantity <= 0 || line.UnitCost < 0 {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			return
+		}
+
+		bestBefores[i], err = parseBestBefore(line.BestBefore)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	ctx := context.Background()
+
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	purchaseOrder, err := qtx.CreatePurchaseOrder(ctx, database.CreatePurchaseOrderParams{
+		SupplierID: newPurchaseOrder.SupplierID,
+		ExpectedAt: expectedAt,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	lines := []database.PurchaseOrderLine{}
+	for i, line := range newPurchaseOrder.Lines {
+		item, err := qtx.GetItemById(ctx, line.ItemID)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "Item not found"}`, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+		if item.IsBundle {
+			http.Error(w, `{"error": "Bundles are stocked through their components"}`, http.StatusBadRequest)
+			return
+		}
+
+		args := database.AddPurchaseOrderLineParams{
+			PurchaseOrderID: purchaseOrder.ID,
+			ItemID:          line.ItemID,
+			Quantity:        int32(line.Quantity),
+			UnitCost:        int32(line.UnitCost),
+			BestBefore:      bestBefores[i],
+		}
+
+		err = qtx.AddPurchaseOrderLine(ctx, args)
+		if err != nil {
+			http.Error(w, `{"error": "Incorrect request data"}`, http.StatusBadRequest)
+			logger.Warn(err)
+			return
+		}
+
+		lines = append(lines, database.PurchaseOrderLine(args))
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(PurchaseOrderWithLines{
+		PurchaseOrder: purchaseOrder,
+		Lines:         lines,
+	})
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write(respData)
+}
+
+// receivePurchaseOrder books every line of an open purchase order into stock
+// as a new batch carrying the supplier's unit cost.
+func (cfg *ApiConfig) receivePurchaseOrder(ctx context.Context, adminID, purchaseOrderID uuid.UUID) (database.PurchaseOrder, error) {
+	tx, err := cfg.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return database.PurchaseOrder{}, err
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	purchaseOrder, err := qtx.ReceivePurchaseOrder(ctx, purchaseOrderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.PurchaseOrder{}, errPurchaseOrderClosed
+	}
+	if err != nil {
+		return database.PurchaseOrder{}, err
+	}
+
+	lines, err := qtx.GetPurchaseOrderLines(ctx, purchaseOrderID)
+	if err != nil {
+		return database.PurchaseOrder{}, err
+	}
+
+	restocked := []restockedItem{}
+	for _, line := range lines {
+		_, err := qtx.CreateItemBatch(ctx, database.CreateItemBatchParams{
+			ItemID:     line.ItemID,
+			ReceivedAt: time.Now(),
+			BestBefore: line.BestBefore,
+			CostPrice:  line.UnitCost,
+			Quantity:   line.Quantity,
+		})
+		if err != nil {
+			return database.PurchaseOrder{}, err
+		}
+
+		item, err := qtx.AddItemStock(ctx, database.AddItemStockParams{
+			Amount: line.Quantity,
+			ID:     line.ItemID,
+		})
+		if err != nil {
+			return database.PurchaseOrder{}, err
+		}
+
+		err = recordStockMovement(ctx, qtx, line.ItemID, line.Quantity, stockMovement{
+			Reason:    stockReasonRestock,
+			ActorID:   uuid.NullUUID{UUID: adminID, Valid: true},
+			Reference: "purchase_order:" + purchaseOrderID.String(),
+		})
+		if err != nil {
+			return database.PurchaseOrder{}, err
+		}
+
+		if item.Quantity == line.Quantity {
+			restocked = append(restocked, restockedItem{ID: line.ItemID, Name: item.Name})
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return database.PurchaseOrder{}, err
+	}
+
+	for _, item := range restocked {
+		go cfg.notifyBackInStock(item.ID, item.Name)
+	}
+
+	return purchaseOrder, nil
+}
+
+func (cfg *ApiConfig) HandlerReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrderID, err := uuid.Parse(r.PathValue("purchaseOrderID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrder, err := cfg.receivePurchaseOrder(context.Background(), adminID, purchaseOrderID)
+	if errors.Is(err, errPurchaseOrderClosed) {
+		http.Error(w, `{"error": "Purchase order is not open"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(purchaseOrder)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+func (cfg *ApiConfig) HandlerCancelPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrderID, err := uuid.Parse(r.PathValue("purchaseOrderID"))
+	if err != nil {
+		http.Error(w, `{"error": "Problem with parsing request data"}`, http.StatusBadRequest)
+		logger.Warn(err)
+		return
+	}
+
+	purchaseOrder, err := cfg.Queries.CancelPurchaseOrder(context.Background(), purchaseOrderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, `{"error": "Purchase order is not open"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	respData, err := json.Marshal(purchaseOrder)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
+
+// HandlerGetMargins reports the selling price of every item against the average
+// unit cost paid to each supplier on received purchase orders.
+func (cfg *ApiConfig) HandlerGetMargins(w http.ResponseWriter, r *http.Request) {
+	token, err := jwt.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	adminID, err := jwt.ValidateJWT(token, cfg.SecretJWT)
+	if err != nil {
+		http.Error(w, `{"error": "Unauthorized user"}`, http.StatusUnauthorized)
+		logger.Warn(err)
+		return
+	}
+
+	email, err := cfg.Queries.GetUserEmail(context.Background(), adminID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if email != cfg.AdminEmail {
+		http.Error(w, `{"error": "Do not have permissions"}`, http.StatusForbidden)
+		logger.Warn(err)
+		return
+	}
+
+	rows, err := cfg.Queries.GetItemMargins(context.Background())
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	margins := []ItemMargin{}
+	for _, row := range rows {
+		margin := ItemMargin{
+			GetItemMarginsRow: row,
+			Margin:            row.Cost - row.AverageUnitCost,
+		}
+		if row.Cost > 0 {
+			margin.MarginPercent = float64(margin.Margin) * 100 / float64(row.Cost)
+		}
+
+		margins = append(margins, margin)
+	}
+
+	respData, err := json.Marshal(margins)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with marshalling answer"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	w.Write(respData)
+}
@@ -6,9 +6,12 @@ import (
 	"HomeFruits/internal/jwt"
 	"HomeFruits/logger"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -17,6 +20,7 @@ type User struct {
 	Password     string `json:"password"`
 	Token        string `json:"JWT,omitempty"`
 	RefreshToken string
+	ReferralCode string `json:"referral_code,omitempty"`
 }
 
 type Token struct {
@@ -47,6 +51,20 @@ func (cfg *ApiConfig) HandlerRegUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	referrer := database.GetReferrerByCodeRow{}
+	if newUser.ReferralCode != "" {
+		referrer, err = cfg.Queries.GetReferrerByCode(context.Background(), strings.ToUpper(strings.TrimSpace(newUser.ReferralCode)))
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, `{"error": "Unknown referral code"}`, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
 	hashedPassword, err := hashfunc.HashingPassword(newUser.Password)
 	if err != nil {
 		http.Error(w, `{"error": "Problem with hashing provided password"}`, http.StatusInternalServerError)
@@ -59,7 +77,40 @@ func (cfg *ApiConfig) HandlerRegUser(w http.ResponseWriter, r *http.Request) {
 		HashedPassword: hashedPassword,
 	}
 
-	createdUser, err := cfg.Queries.CreateNewUser(context.Background(), arg)
+	tx, err := cfg.DB.BeginTx(context.Background(), nil)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+	defer tx.Rollback()
+
+	qtx := cfg.Queries.WithTx(tx)
+
+	createdUser, err := qtx.CreateNewUser(context.Background(), arg)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	_, err = ensureReferralCode(context.Background(), qtx, createdUser.ID)
+	if err != nil {
+		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+		logger.Warn(err)
+		return
+	}
+
+	if newUser.ReferralCode != "" {
+		err = createReferral(context.Background(), qtx, referrer, createdUser)
+		if err != nil {
+			http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
+			logger.Warn(err)
+			return
+		}
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		http.Error(w, `{"error": "Problem with database query"}`, http.StatusInternalServerError)
 		logger.Warn(err)
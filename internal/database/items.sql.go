@@ -11,24 +11,212 @@ import (
 	"github.com/google/uuid"
 )
 
+const addBundleComponent = `-- name: AddBundleComponent :exec
+INSERT INTO bundle_components(bundle_id, item_id, quantity)
+VALUES(
+    $1,
+    $2,
+    $3
+)
+`
+
+type AddBundleComponentParams struct {
+	BundleID uuid.UUID
+	ItemID   uuid.UUID
+	Quantity int32
+}
+
+func (q *Queries) AddBundleComponent(ctx context.Context, arg AddBundleComponentParams) error {
+	_, err := q.db.ExecContext(ctx, addBundleComponent, arg.BundleID, arg.ItemID, arg.Quantity)
+	return err
+}
+
+const addItemStock = `-- name: AddItemStock :one
+UPDATE items
+SET quantity = quantity + $1::INTEGER
+WHERE id = $2
+RETURNING name, quantity
+`
+
+type AddItemStockParams struct {
+	Amount int32
+	ID     uuid.UUID
+}
+
+type AddItemStockRow struct {
+	Name     string
+	Quantity int32
+}
+
+func (q *Queries) AddItemStock(ctx context.Context, arg AddItemStockParams) (AddItemStockRow, error) {
+	row := q.db.QueryRowContext(ctx, addItemStock, arg.Amount, arg.ID)
+	var i AddItemStockRow
+	err := row.Scan(&i.Name, &i.Quantity)
+	return i, err
+}
+
+const createBundle = `-- name: CreateBundle :one
+INSERT INTO items(id, name, quantity, cost, category, is_bundle)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    0,
+    $2,
+    $3,
+    TRUE
+)
+RETURNING id, name, quantity, cost, category, is_bundle, reorder_threshold
+`
+
+type CreateBundleParams struct {
+	Name     string
+	Cost     int32
+	Category string
+}
+
+func (q *Queries) CreateBundle(ctx context.Context, arg CreateBundleParams) (Item, error) {
+	row := q.db.QueryRowContext(ctx, createBundle, arg.Name, arg.Cost, arg.Category)
+	var i Item
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Cost,
+		&i.Category,
+		&i.IsBundle,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
 const getAllItems = `-- name: GetAllItems :many
-SELECT id, name, quantity, cost FROM items
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, items.is_bundle,
+    COALESCE(item_ratings.average_rating, 0)::REAL AS average_rating,
+    COALESCE(item_ratings.review_count, 0)::INTEGER AS review_count
+FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+LEFT JOIN item_ratings ON item_ratings.item_id = items.id
 `
 
-func (q *Queries) GetAllItems(ctx context.Context) ([]Item, error) {
+type GetAllItemsRow struct {
+	ID            uuid.UUID
+	Name          string
+	Quantity      int32
+	Cost          int32
+	Category      string
+	IsBundle      bool
+	AverageRating float32
+	ReviewCount   int32
+}
+
+func (q *Queries) GetAllItems(ctx context.Context) ([]GetAllItemsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getAllItems)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Item
+	var items []GetAllItemsRow
 	for rows.Next() {
-		var i Item
+		var i GetAllItemsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Name,
 			&i.Quantity,
 			&i.Cost,
+			&i.Category,
+			&i.IsBundle,
+			&i.AverageRating,
+			&i.ReviewCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBundleComponents = `-- name: GetBundleComponents :many
+SELECT item_id, quantity FROM bundle_components
+WHERE bundle_id = $1
+`
+
+type GetBundleComponentsRow struct {
+	ItemID   uuid.UUID
+	Quantity int32
+}
+
+func (q *Queries) GetBundleComponents(ctx context.Context, bundleID uuid.UUID) ([]GetBundleComponentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBundleComponents, bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBundleComponentsRow
+	for rows.Next() {
+		var i GetBundleComponentsRow
+		if err := rows.Scan(&i.ItemID, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBundleStock = `-- name: GetBundleStock :one
+SELECT quantity FROM bundle_stock
+WHERE bundle_id = $1
+`
+
+func (q *Queries) GetBundleStock(ctx context.Context, bundleID uuid.UUID) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getBundleStock, bundleID)
+	var quantity int32
+	err := row.Scan(&quantity)
+	return quantity, err
+}
+
+const getCatalog = `-- name: GetCatalog :many
+SELECT items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, items.reorder_threshold FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+ORDER BY items.name
+`
+
+type GetCatalogRow struct {
+	Name             string
+	Quantity         int32
+	Cost             int32
+	Category         string
+	ReorderThreshold int32
+}
+
+func (q *Queries) GetCatalog(ctx context.Context) ([]GetCatalogRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCatalog)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCatalogRow
+	for rows.Next() {
+		var i GetCatalogRow
+		if err := rows.Scan(
+			&i.Name,
+			&i.Quantity,
+			&i.Cost,
+			&i.Category,
+			&i.ReorderThreshold,
 		); err != nil {
 			return nil, err
 		}
@@ -44,64 +232,224 @@ func (q *Queries) GetAllItems(ctx context.Context) ([]Item, error) {
 }
 
 const getItemById = `-- name: GetItemById :one
-SELECT name, cost, quantity FROM items
-WHERE id = $1
+SELECT items.name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, items.is_bundle FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.id = $1
 `
 
 type GetItemByIdRow struct {
 	Name     string
 	Cost     int32
 	Quantity int32
+	IsBundle bool
 }
 
 func (q *Queries) GetItemById(ctx context.Context, id uuid.UUID) (GetItemByIdRow, error) {
 	row := q.db.QueryRowContext(ctx, getItemById, id)
 	var i GetItemByIdRow
-	err := row.Scan(&i.Name, &i.Cost, &i.Quantity)
+	err := row.Scan(
+		&i.Name,
+		&i.Cost,
+		&i.Quantity,
+		&i.IsBundle,
+	)
+	return i, err
+}
+
+const getItemByName = `-- name: GetItemByName :one
+SELECT id, name, quantity, cost, category, is_bundle, reorder_threshold FROM items
+WHERE name = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetItemByName(ctx context.Context, name string) (Item, error) {
+	row := q.db.QueryRowContext(ctx, getItemByName, name)
+	var i Item
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Cost,
+		&i.Category,
+		&i.IsBundle,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const getSubstituteItem = `-- name: GetSubstituteItem :one
+SELECT items.id, items.name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.quantity FROM items
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.category = $1
+    AND items.category <> ''
+    AND NOT items.is_bundle
+    AND items.id <> $2
+    AND items.quantity >= $3::INTEGER
+    AND (
+        NOT EXISTS (SELECT 1 FROM item_seasons WHERE item_seasons.item_id = items.id)
+        OR EXISTS (
+            SELECT 1 FROM item_seasons
+            WHERE item_seasons.item_id = items.id
+                AND CASE
+                    WHEN item_seasons.start_month <= item_seasons.end_month
+                    THEN EXTRACT(MONTH FROM NOW()) BETWEEN item_seasons.start_month AND item_seasons.end_month
+                    ELSE EXTRACT(MONTH FROM NOW()) >= item_seasons.start_month OR EXTRACT(MONTH FROM NOW()) <= item_seasons.end_month
+                END
+        )
+    )
+ORDER BY ABS(COALESCE(item_current_prices.price, items.cost) - $4::INTEGER)
+LIMIT 1
+`
+
+type GetSubstituteItemParams struct {
+	Category   string
+	ID         uuid.UUID
+	Amount     int32
+	TargetCost int32
+}
+
+type GetSubstituteItemRow struct {
+	ID       uuid.UUID
+	Name     string
+	Cost     int32
+	Quantity int32
+}
+
+func (q *Queries) GetSubstituteItem(ctx context.Context, arg GetSubstituteItemParams) (GetSubstituteItemRow, error) {
+	row := q.db.QueryRowContext(ctx, getSubstituteItem,
+		arg.Category,
+		arg.ID,
+		arg.Amount,
+		arg.TargetCost,
+	)
+	var i GetSubstituteItemRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Cost,
+		&i.Quantity,
+	)
 	return i, err
 }
 
 const insertItem = `-- name: InsertItem :one
-INSERT INTO items(id, name, quantity, cost)
+INSERT INTO items(id, name, quantity, cost, category, reorder_threshold)
 VALUES(
     gen_random_uuid(),
     $1,
     $2,
-    $3
+    $3,
+    $4,
+    $5
 )
-RETURNING id, name, quantity, cost
+RETURNING id, name, quantity, cost, category, is_bundle, reorder_threshold
 `
 
 type InsertItemParams struct {
-	Name     string
-	Quantity int32
-	Cost     int32
+	Name             string
+	Quantity         int32
+	Cost             int32
+	Category         string
+	ReorderThreshold int32
 }
 
 func (q *Queries) InsertItem(ctx context.Context, arg InsertItemParams) (Item, error) {
-	row := q.db.QueryRowContext(ctx, insertItem, arg.Name, arg.Quantity, arg.Cost)
+	row := q.db.QueryRowContext(ctx, insertItem,
+		arg.Name,
+		arg.Quantity,
+		arg.Cost,
+		arg.Category,
+		arg.ReorderThreshold,
+	)
 	var i Item
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Quantity,
 		&i.Cost,
+		&i.Category,
+		&i.IsBundle,
+		&i.ReorderThreshold,
 	)
 	return i, err
 }
 
-const updateItemQuantity = `-- name: UpdateItemQuantity :exec
+const setReorderThreshold = `-- name: SetReorderThreshold :one
 UPDATE items
-SET quantity = $1
+SET reorder_threshold = $2
+WHERE id = $1 AND NOT is_bundle
+RETURNING id, name, quantity, cost, category, is_bundle, reorder_threshold
+`
+
+type SetReorderThresholdParams struct {
+	ID               uuid.UUID
+	ReorderThreshold int32
+}
+
+func (q *Queries) SetReorderThreshold(ctx context.Context, arg SetReorderThresholdParams) (Item, error) {
+	row := q.db.QueryRowContext(ctx, setReorderThreshold, arg.ID, arg.ReorderThreshold)
+	var i Item
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Quantity,
+		&i.Cost,
+		&i.Category,
+		&i.IsBundle,
+		&i.ReorderThreshold,
+	)
+	return i, err
+}
+
+const takeItemStock = `-- name: TakeItemStock :execrows
+UPDATE items
+SET quantity = quantity - $1::INTEGER
+WHERE id = $2 AND $1::INTEGER > 0 AND quantity >= $1::INTEGER
+`
+
+type TakeItemStockParams struct {
+	Amount int32
+	ID     uuid.UUID
+}
+
+func (q *Queries) TakeItemStock(ctx context.Context, arg TakeItemStockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, takeItemStock, arg.Amount, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateItemDetails = `-- name: UpdateItemDetails :exec
+UPDATE items
+SET category = $2, reorder_threshold = $3
+WHERE id = $1
+`
+
+type UpdateItemDetailsParams struct {
+	ID               uuid.UUID
+	Category         string
+	ReorderThreshold int32
+}
+
+func (q *Queries) UpdateItemDetails(ctx context.Context, arg UpdateItemDetailsParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemDetails, arg.ID, arg.Category, arg.ReorderThreshold)
+	return err
+}
+
+const writeOffItemStock = `-- name: WriteOffItemStock :exec
+UPDATE items
+SET quantity = quantity - $1::INTEGER
 WHERE id = $2
 `
 
-type UpdateItemQuantityParams struct {
-	Quantity int32
-	ID       uuid.UUID
+type WriteOffItemStockParams struct {
+	Amount int32
+	ID     uuid.UUID
 }
 
-func (q *Queries) UpdateItemQuantity(ctx context.Context, arg UpdateItemQuantityParams) error {
-	_, err := q.db.ExecContext(ctx, updateItemQuantity, arg.Quantity, arg.ID)
+func (q *Queries) WriteOffItemStock(ctx context.Context, arg WriteOffItemStockParams) error {
+	_, err := q.db.ExecContext(ctx, writeOffItemStock, arg.Amount, arg.ID)
 	return err
 }
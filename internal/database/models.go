@@ -6,16 +6,349 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-type Item struct {
+type Address struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Label     string
+	Street    string
+	City      string
+	Postcode  string
+	Latitude  sql.NullFloat64
+	Longitude sql.NullFloat64
+	CreatedAt time.Time
+}
+
+type BoxReservation struct {
+	UserID         uuid.UUID
+	ItemID         uuid.UUID
+	SubscriptionID uuid.UUID
+	ReleaseAt      time.Time
+}
+
+type BundleComponent struct {
+	BundleID uuid.UUID
+	ItemID   uuid.UUID
+	Quantity int32
+}
+
+type BundleStock struct {
+	BundleID uuid.UUID
+	Quantity int32
+}
+
+type BuyAgainRecommendation struct {
+	UserID       uuid.UUID
+	ItemID       uuid.UUID
+	TimesBought  int32
+	LastBoughtAt time.Time
+}
+
+type CartBatchAllocation struct {
+	UserID   uuid.UUID
+	ItemID   uuid.UUID
+	BatchID  uuid.UUID
+	Quantity int32
+}
+
+type CartCoupon struct {
+	UserID   uuid.UUID
+	CouponID uuid.UUID
+}
+
+type CartPointRedemption struct {
+	UserID uuid.UUID
+	Points int32
+}
+
+type CartSlot struct {
+	UserID uuid.UUID
+	SlotID uuid.UUID
+}
+
+type Coupon struct {
+	ID           uuid.UUID
+	Code         string
+	DiscountType string
+	Value        int32
+	MaxUses      sql.NullInt32
+	PerUserLimit int32
+	MinBasket    int32
+	TimesUsed    int32
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+type CouponRedemption struct {
+	ID         uuid.UUID
+	CouponID   uuid.UUID
+	UserID     uuid.UUID
+	RedeemedAt time.Time
+}
+
+type CreditMovement struct {
+	ID         uuid.UUID
+	GiftCardID uuid.NullUUID
+	UserID     uuid.NullUUID
+	Delta      int32
+	Reason     string
+	ActorID    uuid.NullUUID
+	Reference  string
+	CreatedAt  time.Time
+}
+
+type DeliveryBooking struct {
+	PaymentID uuid.UUID
+	SlotID    uuid.UUID
+	UserID    uuid.UUID
+	AddressID uuid.NullUUID
+	CreatedAt time.Time
+}
+
+type DeliverySlot struct {
 	ID       uuid.UUID
-	Name     string
+	ZoneID   uuid.UUID
+	Day      time.Time
+	StartsAt time.Time
+	EndsAt   time.Time
+	Capacity int32
+	Booked   int32
+}
+
+type DeliveryZone struct {
+	ID            uuid.UUID
+	Name          string
+	Postcodes     []string
+	Polygon       json.RawMessage
+	Fee           int32
+	FreeThreshold sql.NullInt32
+	CreatedAt     time.Time
+}
+
+type GiftCard struct {
+	ID         uuid.UUID
+	Code       string
+	Amount     int32
+	IssuedBy   uuid.NullUUID
+	ExpiresAt  sql.NullTime
+	RedeemedBy uuid.NullUUID
+	RedeemedAt sql.NullTime
+	CreatedAt  time.Time
+}
+
+type IdempotencyKey struct {
+	Scope           string
+	Key             string
+	Fingerprint     string
+	StatusCode      sql.NullInt32
+	ResponseBody    []byte
+	ResponseHeaders json.RawMessage
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+}
+
+type Item struct {
+	ID               uuid.UUID
+	Name             string
+	Quantity         int32
+	Cost             int32
+	Category         string
+	IsBundle         bool
+	ReorderThreshold int32
+}
+
+type ItemBatch struct {
+	ID           uuid.UUID
+	ItemID       uuid.UUID
+	ReceivedAt   time.Time
+	BestBefore   sql.NullTime
+	CostPrice    int32
+	Quantity     int32
+	WrittenOffAt sql.NullTime
+}
+
+type ItemCurrentPrice struct {
+	ItemID uuid.UUID
+	Price  int32
+}
+
+type ItemDetail struct {
+	ItemID         uuid.UUID
+	Description    string
+	OriginCountry  string
+	IsOrganic      bool
+	Certifications []string
+	Nutrition      json.RawMessage
+	Allergens      string
+	UpdatedAt      time.Time
+	Tags           []string
+}
+
+type ItemImage struct {
+	ID           uuid.UUID
+	ItemID       uuid.UUID
+	OriginalKey  string
+	ThumbnailKey string
+	MediumKey    string
+	ContentType  string
+	CreatedAt    time.Time
+}
+
+type ItemPrice struct {
+	ID            uuid.UUID
+	ItemID        uuid.UUID
+	Price         int32
+	EffectiveFrom time.Time
+	CreatedBy     uuid.NullUUID
+	CreatedAt     time.Time
+}
+
+type ItemRating struct {
+	ItemID        uuid.UUID
+	AverageRating float32
+	ReviewCount   int32
+}
+
+type ItemRecommendation struct {
+	ItemID            uuid.UUID
+	RecommendedItemID uuid.UUID
+	Score             int32
+}
+
+type ItemSearch struct {
+	ItemID       uuid.UUID
+	Document     string
+	SearchVector interface{}
+}
+
+type ItemSeason struct {
+	ID         uuid.UUID
+	ItemID     uuid.UUID
+	StartMonth int32
+	EndMonth   int32
+	CreatedAt  time.Time
+}
+
+type LowStockAlert struct {
+	ItemID    uuid.UUID
+	AlertedAt time.Time
+}
+
+type LoyaltyPointLot struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	PaymentID uuid.NullUUID
+	Points    int32
+	Remaining int32
+	EarnedAt  time.Time
+	ExpiresAt time.Time
+	ExpiredAt sql.NullTime
+}
+
+type LoyaltyPointMovement struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Delta     int32
+	Reason    string
+	Reference string
+	CreatedAt time.Time
+}
+
+type Payment struct {
+	ID               uuid.UUID
+	UserID           uuid.UUID
+	ProviderIntentID sql.NullString
+	Amount           int32
+	Status           string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StoreCredit      int32
+	AddressID        uuid.NullUUID
+	DeliveryFee      int32
+	SlotID           uuid.NullUUID
+	CouponID         uuid.NullUUID
+}
+
+type PaymentEvent struct {
+	ID               string
+	Type             string
+	ProviderIntentID string
+	ReceivedAt       time.Time
+}
+
+type PaymentItem struct {
+	PaymentID uuid.UUID
+	ItemID    uuid.UUID
+	Quantity  int32
+	Cost      int32
+}
+
+type PreOrder struct {
+	UserID    uuid.UUID
+	ItemID    uuid.UUID
+	Quantity  int32
+	CreatedAt time.Time
+}
+
+type Promotion struct {
+	ID           uuid.UUID
+	Name         string
+	RuleType     string
+	ItemID       uuid.NullUUID
+	Category     string
+	Percent      int32
+	Amount       int32
+	BuyQuantity  int32
+	FreeQuantity int32
+	StartsAt     time.Time
+	EndsAt       time.Time
+	CreatedAt    time.Time
+}
+
+type Purchase struct {
+	UserID   uuid.NullUUID
+	ItemID   uuid.UUID
 	Quantity int32
-	Cost     int32
+	BoughtAt time.Time
+}
+
+type PurchaseOrder struct {
+	ID         uuid.UUID
+	SupplierID uuid.UUID
+	Status     string
+	ExpectedAt time.Time
+	CreatedAt  time.Time
+	ReceivedAt sql.NullTime
+}
+
+type PurchaseOrderLine struct {
+	PurchaseOrderID uuid.UUID
+	ItemID          uuid.UUID
+	Quantity        int32
+	UnitCost        int32
+	BestBefore      sql.NullTime
+}
+
+type Referral struct {
+	ID              uuid.UUID
+	ReferrerID      uuid.UUID
+	RefereeID       uuid.UUID
+	Status          string
+	RejectionReason string
+	PaymentID       uuid.NullUUID
+	CreatedAt       time.Time
+	CompletedAt     sql.NullTime
+}
+
+type ReferralCode struct {
+	UserID    uuid.UUID
+	Code      string
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -25,6 +358,36 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Refund struct {
+	ID               uuid.UUID
+	PaymentID        uuid.UUID
+	ProviderRefundID sql.NullString
+	Amount           int32
+	Status           string
+	Reason           string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StoreCredit      int32
+}
+
+type RefundItem struct {
+	RefundID  uuid.UUID
+	ItemID    uuid.UUID
+	Quantity  int32
+	Restocked bool
+}
+
+type Review struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ItemID    uuid.UUID
+	Rating    int32
+	Body      string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type ShoppingCart struct {
 	ItemID   uuid.UUID
 	UserID   uuid.UUID
@@ -33,6 +396,51 @@ type ShoppingCart struct {
 	ItemName string
 }
 
+type StockMovement struct {
+	ID        uuid.UUID
+	ItemID    uuid.UUID
+	Delta     int32
+	Reason    string
+	ActorID   uuid.NullUUID
+	Reference string
+	CreatedAt time.Time
+}
+
+type StockSubscription struct {
+	UserID    uuid.UUID
+	ItemID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type StoreCreditAccount struct {
+	UserID  uuid.UUID
+	Balance int32
+}
+
+type Subscription struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Cadence   string
+	Status    string
+	SkipNext  bool
+	NextRunAt time.Time
+	CreatedAt time.Time
+}
+
+type SubscriptionItem struct {
+	SubscriptionID uuid.UUID
+	ItemID         uuid.UUID
+	Quantity       int32
+}
+
+type Supplier struct {
+	ID        uuid.UUID
+	Name      string
+	Email     string
+	Phone     string
+	CreatedAt time.Time
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      sql.NullTime
@@ -40,3 +448,9 @@ type User struct {
 	Email          string
 	HashedPassword string
 }
+
+type Wishlist struct {
+	UserID    uuid.UUID
+	ItemID    uuid.UUID
+	CreatedAt time.Time
+}
@@ -41,6 +41,16 @@ func (q *Queries) AddItemInCart(ctx context.Context, arg AddItemInCartParams) er
 	return err
 }
 
+const clearShoppingCart = `-- name: ClearShoppingCart :exec
+DELETE FROM shopping_cart
+WHERE user_id = $1
+`
+
+func (q *Queries) ClearShoppingCart(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, clearShoppingCart, userID)
+	return err
+}
+
 const deleteFromCart = `-- name: DeleteFromCart :one
 DELETE FROM shopping_cart
 WHERE item_id = $1 AND user_id = $2
@@ -98,3 +108,44 @@ func (q *Queries) GetShoppingCart(ctx context.Context, userID uuid.UUID) ([]Shop
 	}
 	return items, nil
 }
+
+const getShoppingCartForPricing = `-- name: GetShoppingCartForPricing :many
+SELECT shopping_cart.item_id, shopping_cart.quantity, shopping_cart.cost, items.category FROM shopping_cart
+JOIN items ON items.id = shopping_cart.item_id
+WHERE shopping_cart.user_id = $1
+`
+
+type GetShoppingCartForPricingRow struct {
+	ItemID   uuid.UUID
+	Quantity int32
+	Cost     int32
+	Category string
+}
+
+func (q *Queries) GetShoppingCartForPricing(ctx context.Context, userID uuid.UUID) ([]GetShoppingCartForPricingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getShoppingCartForPricing, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetShoppingCartForPricingRow
+	for rows.Next() {
+		var i GetShoppingCartForPricingRow
+		if err := rows.Scan(
+			&i.ItemID,
+			&i.Quantity,
+			&i.Cost,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,82 @@
+package delivery
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var ErrOutsideZones = errors.New("address is outside of delivery zones")
+
+type Point struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Zone is matched either by postcode or, when the address has coordinates, by polygon.
+// FreeThreshold of 0 means delivery is never free.
+type Zone struct {
+	ID            uuid.UUID
+	Name          string
+	Postcodes     []string
+	Polygon       []Point
+	Fee           int
+	FreeThreshold int
+}
+
+type Address struct {
+	Postcode string
+	Location *Point
+}
+
+func NormalizePostcode(postcode string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(postcode), " ", ""))
+}
+
+// MatchZone returns the first zone serving the address. Postcode matches win over polygon matches.
+func MatchZone(zones []Zone, addr Address) (Zone, error) {
+	postcode := NormalizePostcode(addr.Postcode)
+	for _, zone := range zones {
+		for _, p := range zone.Postcodes {
+			if NormalizePostcode(p) == postcode {
+				return zone, nil
+			}
+		}
+	}
+
+	if addr.Location != nil {
+		for _, zone := range zones {
+			if len(zone.Polygon) >= 3 && contains(zone.Polygon, *addr.Location) {
+				return zone, nil
+			}
+		}
+	}
+
+	return Zone{}, ErrOutsideZones
+}
+
+// FeeFor is the delivery price for a basket of the given total.
+func (zone Zone) FeeFor(basketTotal int) int {
+	if zone.FreeThreshold > 0 && basketTotal >= zone.FreeThreshold {
+		return 0
+	}
+
+	return zone.Fee
+}
+
+// contains is a ray casting point in polygon test, good enough for city sized zones.
+func contains(polygon []Point, p Point) bool {
+	inside := false
+	j := len(polygon) - 1
+	for i := range polygon {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+		j = i
+	}
+
+	return inside
+}
@@ -0,0 +1,149 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	MaxUploadSize = 5 << 20
+	MaxDimension  = 6000
+	// MaxPixels keeps the decoded bitmap and its flattened copy around 64MB each.
+	MaxPixels     = 16 << 20
+	ThumbnailSize = 200
+	MediumSize    = 800
+	jpegQuality   = 85
+)
+
+var (
+	ErrTooLarge        = errors.New("image is too large")
+	ErrUnsupportedType = errors.New("only jpeg and png images are supported")
+)
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// Processed is a validated upload with its resized variants, both encoded as JPEG.
+type Processed struct {
+	ContentType string
+	Extension   string
+	Thumbnail   []byte
+	Medium      []byte
+}
+
+// Process checks the type and size of an uploaded image by its content rather than
+// its name, then renders the thumbnail and medium variants.
+func Process(data []byte) (Processed, error) {
+	if len(data) > MaxUploadSize {
+		return Processed{}, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := extensions[contentType]
+	if !ok {
+		return Processed{}, ErrUnsupportedType
+	}
+
+	// Check the dimensions before decoding, a small file can still expand into a huge bitmap.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, ErrUnsupportedType
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension || config.Width*config.Height > MaxPixels {
+		return Processed{}, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, ErrUnsupportedType
+	}
+
+	// Both variants are resized from one flattened copy.
+	flat := Flatten(img)
+
+	thumbnail, err := encode(Resize(flat, ThumbnailSize))
+	if err != nil {
+		return Processed{}, err
+	}
+
+	medium, err := encode(Resize(flat, MediumSize))
+	if err != nil {
+		return Processed{}, err
+	}
+
+	return Processed{
+		ContentType: contentType,
+		Extension:   extension,
+		Thumbnail:   thumbnail,
+		Medium:      medium,
+	}, nil
+}
+
+// Flatten draws an image onto white, transparent pixels would turn black in a JPEG.
+func Flatten(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), src, bounds.Min, draw.Over)
+
+	return flat
+}
+
+// Resize scales a flattened image down so that its longer side is at most maxSide, averaging
+// the source pixels under every target pixel. Smaller images are returned as they are.
+func Resize(flat *image.RGBA, maxSide int) *image.RGBA {
+	width, height := flat.Bounds().Dx(), flat.Bounds().Dy()
+	if width <= maxSide && height <= maxSide {
+		return flat
+	}
+
+	scale := float64(maxSide) / float64(max(width, height))
+	dstWidth := max(1, int(float64(width)*scale+0.5))
+	dstHeight := max(1, int(float64(height)*scale+0.5))
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0 := y * height / dstHeight
+		y1 := max(y0+1, (y+1)*height/dstHeight)
+
+		for x := 0; x < dstWidth; x++ {
+			x0 := x * width / dstWidth
+			x1 := max(x0+1, (x+1)*width/dstWidth)
+
+			var r, g, b, n int
+			for sy := y0; sy < y1; sy++ {
+				row := flat.Pix[sy*flat.Stride+x0*4 : sy*flat.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += int(row[i])
+					g += int(row[i+1])
+					b += int(row[i+2])
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(x, y)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 255
+		}
+	}
+
+	return dst
+}
+
+func encode(img image.Image) ([]byte, error) {
+	buf := bytes.Buffer{}
+	err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
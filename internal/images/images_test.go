@@ -0,0 +1,53 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestProcessResizesFromOneFlatCopy(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1600, 400))
+	for y := 0; y < 400; y++ {
+		for x := 0; x < 800; x++ {
+			src.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	buf := bytes.Buffer{}
+	err := png.Encode(&buf, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err := Process(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, variant := range []struct {
+		name          string
+		data          []byte
+		width, height int
+	}{
+		{"thumbnail", processed.Thumbnail, ThumbnailSize, ThumbnailSize / 4},
+		{"medium", processed.Medium, MediumSize, MediumSize / 4},
+	} {
+		img, err := jpeg.Decode(bytes.NewReader(variant.data))
+		if err != nil {
+			t.Fatalf("%s: %v", variant.name, err)
+		}
+		if img.Bounds().Dx() != variant.width || img.Bounds().Dy() != variant.height {
+			t.Fatalf("%s is %v, want %dx%d", variant.name, img.Bounds().Size(), variant.width, variant.height)
+		}
+
+		// The transparent right half is flattened onto white, not black.
+		r, g, b, _ := img.At(variant.width-1, variant.height/2).RGBA()
+		if r>>8 < 240 || g>>8 < 240 || b>>8 < 240 {
+			t.Fatalf("%s: transparent pixel came out as %d,%d,%d", variant.name, r>>8, g>>8, b>>8)
+		}
+	}
+}
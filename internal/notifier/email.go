@@ -0,0 +1,40 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewEmailNotifier(host, port, user, password, from string) (*EmailNotifier, error) {
+	if host == "" || from == "" {
+		return nil, errors.New("SMTP_HOST and SMTP_FROM are required for email notifier")
+	}
+	if port == "" {
+		port = "587"
+	}
+
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &EmailNotifier{
+		addr: net.JoinHostPort(host, port),
+		auth: auth,
+		from: from,
+	}, nil
+}
+
+func (e *EmailNotifier) Notify(ctx context.Context, n Notification) error {
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", e.from, n.To, n.Subject, n.Message)
+	return smtp.SendMail(e.addr, e.auth, e.from, []string{n.To}, []byte(msg))
+}
@@ -0,0 +1,14 @@
+package notifier
+
+import (
+	"HomeFruits/logger"
+	"context"
+	"fmt"
+)
+
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	logger.Info(fmt.Sprintf("Notification to %s: %s - %s", n.To, n.Subject, n.Message))
+	return nil
+}
@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Message string `json:"message"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NewFromEnv picks a notifier by the NOTIFIER variable: "log" (default), "email" or "webhook".
+func NewFromEnv() (Notifier, error) {
+	switch os.Getenv("NOTIFIER") {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "email":
+		return NewEmailNotifier(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USER"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("SMTP_FROM"),
+		)
+	case "webhook":
+		return NewWebhookNotifier(os.Getenv("NOTIFIER_WEBHOOK_URL"))
+	default:
+		return nil, fmt.Errorf("unknown notifier: %s", os.Getenv("NOTIFIER"))
+	}
+}
@@ -0,0 +1,212 @@
+package payment
+
+import (
+	"HomeFruits/logger"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment methods understood by the mock provider.
+const (
+	MockMethodSuccess = "mock_success"
+	MockMethodDecline = "mock_decline"
+	MockMethod3DS     = "mock_3ds"
+)
+
+// webhookTolerance bounds how old a signed event may be before it is rejected as a replay.
+const webhookTolerance = 5 * time.Minute
+
+var ErrUnknownIntent = errors.New("unknown payment intent")
+
+type mockIntent struct {
+	Intent
+	method string
+}
+
+// MockProvider keeps intents in memory and reports results through signed webhooks
+// sent to webhookURL, like a real gateway would. 3-DS payments are confirmed after
+// three times the usual delay to simulate the customer authenticating.
+type MockProvider struct {
+	secret     string
+	webhookURL string
+	delay      time.Duration
+	client     *http.Client
+
+	mu      sync.Mutex
+	intents map[string]*mockIntent
+}
+
+func NewMockProvider(secret, webhookURL string, delay time.Duration) (*MockProvider, error) {
+	if secret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET is required for mock payment provider")
+	}
+	if webhookURL == "" {
+		webhookURL = "http://localhost:8080/api/payments/webhook"
+	}
+
+	return &MockProvider{
+		secret:     secret,
+		webhookURL: webhookURL,
+		delay:      delay,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		intents:    map[string]*mockIntent{},
+	}, nil
+}
+
+func (m *MockProvider) CreateIntent(ctx context.Context, params IntentParams) (Intent, error) {
+	if params.Amount <= 0 {
+		return Intent{}, errors.New("amount must be positive")
+	}
+
+	status := StatusRequiresCapture
+	switch params.PaymentMethod {
+	case MockMethodSuccess, MockMethodDecline:
+	case MockMethod3DS:
+		status = StatusRequiresAction
+	default:
+		return Intent{}, fmt.Errorf("unknown payment method: %s", params.PaymentMethod)
+	}
+
+	intent := &mockIntent{
+		Intent: Intent{
+			ID:     "pi_" + uuid.NewString(),
+			Amount: params.Amount,
+			Status: status,
+		},
+		method: params.PaymentMethod,
+	}
+
+	m.mu.Lock()
+	m.intents[intent.ID] = intent
+	m.mu.Unlock()
+
+	return intent.Intent, nil
+}
+
+func (m *MockProvider) Capture(ctx context.Context, intentID string) (Intent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	intent, ok := m.intents[intentID]
+	if !ok {
+		return Intent{}, ErrUnknownIntent
+	}
+	if intent.Status != StatusRequiresCapture && intent.Status != StatusRequiresAction {
+		return intent.Intent, nil
+	}
+
+	delay := m.delay
+	eventType := EventPaymentSucceeded
+	finalStatus := StatusSucceeded
+	switch intent.method {
+	case MockMethodDecline:
+		eventType = EventPaymentFailed
+		finalStatus = StatusFailed
+	case MockMethod3DS:
+		delay = 3 * m.delay
+	}
+
+	intent.Status = StatusProcessing
+	go func() {
+		time.Sleep(delay)
+
+		m.mu.Lock()
+		intent.Status = finalStatus
+		m.mu.Unlock()
+
+		m.sendEvent(Event{
+			Type:     eventType,
+			IntentID: intent.ID,
+			Amount:   intent.Amount,
+		})
+	}()
+
+	return intent.Intent, nil
+}
+
+func (m *MockProvider) Refund(ctx context.Context, intentID string, amount int) (Refund, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	intent, ok := m.intents[intentID]
+	if !ok {
+		return Refund{}, ErrUnknownIntent
+	}
+	if intent.Status != StatusSucceeded {
+		return Refund{}, errors.New("payment is not captured")
+	}
+	if amount <= 0 || amount > intent.Amount {
+		return Refund{}, errors.New("incorrect refund amount")
+	}
+
+	refund := Refund{
+		ID:       "re_" + uuid.NewString(),
+		IntentID: intentID,
+		Amount:   amount,
+		Status:   StatusProcessing,
+	}
+
+	go func() {
+		time.Sleep(m.delay)
+		m.sendEvent(Event{
+			Type:     EventRefundSucceeded,
+			IntentID: intentID,
+			RefundID: refund.ID,
+			Amount:   amount,
+		})
+	}()
+
+	return refund, nil
+}
+
+func (m *MockProvider) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	if !validSignature(payload, signature, m.secret) {
+		return Event{}, ErrBadSignature
+	}
+
+	event := Event{}
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return Event{}, err
+	}
+
+	if time.Since(event.CreatedAt) > webhookTolerance {
+		return Event{}, errors.New("webhook event is too old")
+	}
+
+	return event, nil
+}
+
+func (m *MockProvider) sendEvent(event Event) {
+	event.ID = "evt_" + uuid.NewString()
+	event.CreatedAt = time.Now().UTC()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Warn(err, "mock payment provider could not encode event")
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, m.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		logger.Warn(err, "mock payment provider could not build webhook")
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Payment-Signature", Sign(payload, m.secret))
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		logger.Warn(err, "mock payment provider could not deliver webhook")
+		return
+	}
+	resp.Body.Close()
+}
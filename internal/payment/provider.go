@@ -0,0 +1,80 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	StatusRequiresCapture = "requires_capture"
+	StatusRequiresAction  = "requires_action"
+	StatusProcessing      = "processing"
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+)
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventRefundSucceeded  = "refund.succeeded"
+)
+
+var ErrBadSignature = errors.New("webhook signature mismatch")
+
+type IntentParams struct {
+	Amount        int
+	Reference     string
+	PaymentMethod string
+}
+
+type Intent struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+	Status string `json:"status"`
+}
+
+type Refund struct {
+	ID       string `json:"id"`
+	IntentID string `json:"intent_id"`
+	Amount   int    `json:"amount"`
+	Status   string `json:"status"`
+}
+
+// Event is a webhook delivery. ID is unique per event, providers may deliver the same event more than once.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	IntentID  string    `json:"intent_id"`
+	RefundID  string    `json:"refund_id,omitempty"`
+	Amount    int       `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Provider interface {
+	CreateIntent(ctx context.Context, params IntentParams) (Intent, error)
+	Capture(ctx context.Context, intentID string) (Intent, error)
+	Refund(ctx context.Context, intentID string, amount int) (Refund, error)
+	VerifyWebhook(payload []byte, signature string) (Event, error)
+}
+
+// NewFromEnv builds the provider named by PAYMENT_PROVIDER. Only "mock" exists for now.
+func NewFromEnv() (Provider, error) {
+	switch os.Getenv("PAYMENT_PROVIDER") {
+	case "", "mock":
+		delay := 2 * time.Second
+		if rawDelay := os.Getenv("PAYMENT_MOCK_DELAY"); rawDelay != "" {
+			parsed, err := time.ParseDuration(rawDelay)
+			if err != nil {
+				return nil, err
+			}
+			delay = parsed
+		}
+
+		return NewMockProvider(os.Getenv("PAYMENT_WEBHOOK_SECRET"), os.Getenv("PAYMENT_WEBHOOK_URL"), delay)
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", os.Getenv("PAYMENT_PROVIDER"))
+	}
+}
@@ -0,0 +1,28 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Sign returns the hex HMAC-SHA256 of payload, sent in the X-Payment-Signature header.
+func Sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(payload []byte, signature, secret string) bool {
+	expected, err := hex.DecodeString(Sign(payload, secret))
+	if err != nil {
+		return false
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, got)
+}
@@ -0,0 +1,68 @@
+package promotions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrCouponExpired  = errors.New("coupon is expired")
+	ErrBasketTooSmall = errors.New("basket is below coupon minimum")
+)
+
+// Coupon is a discount code. Unlike promotions it is applied on top of them,
+// to the total that is left after promotions.
+type Coupon struct {
+	ID        uuid.UUID
+	Code      string
+	Type      string
+	Value     int
+	MinBasket int
+	ExpiresAt time.Time
+}
+
+func ValidateCoupon(c Coupon) error {
+	if c.Code == "" {
+		return errors.New("code is required")
+	}
+
+	switch c.Type {
+	case Percentage:
+		if c.Value <= 0 || c.Value > 100 {
+			return errors.New("value must be between 1 and 100")
+		}
+	case Fixed:
+		if c.Value <= 0 {
+			return errors.New("value must be positive")
+		}
+	default:
+		return errors.New("unknown discount type")
+	}
+
+	return nil
+}
+
+func ApplyCoupon(res Result, c Coupon, now time.Time) (Result, error) {
+	if !now.Before(c.ExpiresAt) {
+		return res, ErrCouponExpired
+	}
+	if res.Total < c.MinBasket {
+		return res, ErrBasketTooSmall
+	}
+
+	amount := 0
+	if c.Type == Percentage {
+		amount = res.Total * c.Value / 100
+	} else {
+		amount = min(c.Value, res.Total)
+	}
+
+	res.Coupon = c.Code
+	res.CouponDiscount = amount
+	res.Discount += amount
+	res.Total -= amount
+
+	return res, nil
+}
@@ -0,0 +1,13 @@
+package promotions
+
+// ApplyPoints spends loyalty points on what is left to pay after promotions and coupons,
+// one point covers one unit of the total and the total never goes below zero.
+func ApplyPoints(res Result, points int) Result {
+	amount := min(max(points, 0), res.Total)
+
+	res.PointsDiscount = amount
+	res.Discount += amount
+	res.Total -= amount
+
+	return res
+}
@@ -0,0 +1,222 @@
+package promotions
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	Percentage = "percentage"
+	Fixed      = "fixed"
+	BuyXGetY   = "buy_x_get_y"
+	Category   = "category"
+)
+
+// Rule is a promotion as stored in the promotions table.
+// Percentage and Fixed rules apply to ItemID when it is set and to the whole cart otherwise.
+// BuyXGetY always needs ItemID, Category rules need Category.
+type Rule struct {
+	ID           uuid.UUID
+	Name         string
+	Type         string
+	ItemID       uuid.NullUUID
+	Category     string
+	Percent      int
+	Amount       int
+	BuyQuantity  int
+	FreeQuantity int
+	StartsAt     time.Time
+	EndsAt       time.Time
+}
+
+// Line is one cart position. Cost is the total cost of the line, not of a single unit.
+type Line struct {
+	ItemID   uuid.UUID
+	Category string
+	Quantity int
+	Cost     int
+}
+
+type Discount struct {
+	PromotionID uuid.UUID  `json:"promotion_id"`
+	Name        string     `json:"name"`
+	ItemID      *uuid.UUID `json:"item_id,omitempty"`
+	Amount      int        `json:"amount"`
+}
+
+type Result struct {
+	Subtotal       int        `json:"subtotal"`
+	Discount       int        `json:"discount"`
+	Total          int        `json:"total"`
+	Discounts      []Discount `json:"discounts"`
+	Coupon         string     `json:"coupon,omitempty"`
+	CouponDiscount int        `json:"coupon_discount,omitempty"`
+	PointsDiscount int        `json:"points_discount,omitempty"`
+}
+
+func Validate(rule Rule) error {
+	if rule.Name == "" {
+		return errors.New("name is required")
+	}
+	if !rule.EndsAt.After(rule.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	switch rule.Type {
+	case Percentage:
+		if rule.Percent <= 0 || rule.Percent > 100 {
+			return errors.New("percent must be between 1 and 100")
+		}
+	case Fixed:
+		if rule.Amount <= 0 {
+			return errors.New("amount must be positive")
+		}
+	case BuyXGetY:
+		if !rule.ItemID.Valid {
+			return errors.New("item_id is required")
+		}
+		if rule.BuyQuantity <= 0 || rule.FreeQuantity <= 0 {
+			return errors.New("buy_quantity and free_quantity must be positive")
+		}
+	case Category:
+		if rule.Category == "" {
+			return errors.New("category is required")
+		}
+		if rule.Percent <= 0 || rule.Percent > 100 {
+			return errors.New("percent must be between 1 and 100")
+		}
+	default:
+		return errors.New("unknown rule type")
+	}
+
+	return nil
+}
+
+func (rule Rule) activeAt(now time.Time) bool {
+	return !now.Before(rule.StartsAt) && now.Before(rule.EndsAt)
+}
+
+func (rule Rule) cartWide() bool {
+	return (rule.Type == Percentage || rule.Type == Fixed) && !rule.ItemID.Valid
+}
+
+// lineDiscount returns how much rule takes off line, or 0 if the rule does not match it.
+func (rule Rule) lineDiscount(line Line) int {
+	if line.Quantity <= 0 {
+		return 0
+	}
+	unitCost := line.Cost / line.Quantity
+
+	switch rule.Type {
+	case Percentage:
+		if rule.ItemID.UUID != line.ItemID {
+			return 0
+		}
+		return line.Cost * rule.Percent / 100
+	case Fixed:
+		if rule.ItemID.UUID != line.ItemID {
+			return 0
+		}
+		return min(rule.Amount*line.Quantity, line.Cost)
+	case BuyXGetY:
+		if rule.ItemID.UUID != line.ItemID {
+			return 0
+		}
+		sets := line.Quantity / (rule.BuyQuantity + rule.FreeQuantity)
+		return sets * rule.FreeQuantity * unitCost
+	case Category:
+		if rule.Category != line.Category {
+			return 0
+		}
+		return line.Cost * rule.Percent / 100
+	}
+
+	return 0
+}
+
+// Apply prices the cart. Promotions do not stack: every line gets the single best
+// item or category promotion, then the best cart-wide promotion is applied to what is left.
+func Apply(lines []Line, rules []Rule, now time.Time) Result {
+	res := Result{Discounts: []Discount{}}
+
+	for _, line := range mergeLines(lines) {
+		res.Subtotal += line.Cost
+
+		var best *Rule
+		bestAmount := 0
+		for i := range rules {
+			if !rules[i].activeAt(now) || rules[i].cartWide() {
+				continue
+			}
+			amount := rules[i].lineDiscount(line)
+			if amount > bestAmount {
+				best = &rules[i]
+				bestAmount = amount
+			}
+		}
+
+		if best != nil {
+			itemID := line.ItemID
+			res.Discount += bestAmount
+			res.Discounts = append(res.Discounts, Discount{
+				PromotionID: best.ID,
+				Name:        best.Name,
+				ItemID:      &itemID,
+				Amount:      bestAmount,
+			})
+		}
+	}
+
+	remaining := res.Subtotal - res.Discount
+
+	var best *Rule
+	bestAmount := 0
+	for i := range rules {
+		if !rules[i].activeAt(now) || !rules[i].cartWide() {
+			continue
+		}
+		amount := 0
+		if rules[i].Type == Percentage {
+			amount = remaining * rules[i].Percent / 100
+		} else {
+			amount = min(rules[i].Amount, remaining)
+		}
+		if amount > bestAmount {
+			best = &rules[i]
+			bestAmount = amount
+		}
+	}
+
+	if best != nil {
+		res.Discount += bestAmount
+		res.Discounts = append(res.Discounts, Discount{
+			PromotionID: best.ID,
+			Name:        best.Name,
+			Amount:      bestAmount,
+		})
+	}
+
+	res.Total = res.Subtotal - res.Discount
+
+	return res
+}
+
+// mergeLines folds cart rows of the same item together, so quantity based rules see the whole amount.
+func mergeLines(lines []Line) []Line {
+	merged := []Line{}
+	index := map[uuid.UUID]int{}
+
+	for _, line := range lines {
+		if i, ok := index[line.ItemID]; ok {
+			merged[i].Quantity += line.Quantity
+			merged[i].Cost += line.Cost
+			continue
+		}
+		index[line.ItemID] = len(merged)
+		merged = append(merged, line)
+	}
+
+	return merged
+}
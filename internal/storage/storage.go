@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Object is a stored file opened for reading, the caller closes Body.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+}
+
+type Storage interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewFromEnv picks a storage by the STORAGE variable: "local" (default) or "s3".
+// Any S3-compatible server works, MinIO makes a good local stand-in.
+func NewFromEnv() (Storage, error) {
+	switch os.Getenv("STORAGE") {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStorage(dir)
+	case "s3":
+		return NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			os.Getenv("S3_REGION"),
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_ACCESS_KEY"),
+			os.Getenv("S3_SECRET_KEY"),
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage: %s", os.Getenv("STORAGE"))
+	}
+}
@@ -2,19 +2,29 @@ package main
 
 import (
 	"HomeFruits/internal/database"
+	"HomeFruits/internal/notifier"
+	"HomeFruits/internal/payment"
+	"HomeFruits/internal/storage"
 	"HomeFruits/logger"
 	"database/sql"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
 
 type ApiConfig struct {
-	Queries    *database.Queries
-	SecretJWT  string
-	AdminEmail string
+	DB             *sql.DB
+	Queries        *database.Queries
+	SecretJWT      string
+	AdminEmail     string
+	Notifier       notifier.Notifier
+	Payments       payment.Provider
+	Storage        storage.Storage
+	IdempotencyTTL time.Duration
+	PointsTTL      time.Duration
 }
 
 func main() {
@@ -34,30 +44,187 @@ func main() {
 
 	adminEmail := os.Getenv("ADMIN_EMAIL")
 
+	notify, err := notifier.NewFromEnv()
+	if err != nil {
+		logger.HaltOnErr(err)
+	}
+
+	payments, err := payment.NewFromEnv()
+	if err != nil {
+		logger.HaltOnErr(err)
+	}
+
+	files, err := storage.NewFromEnv()
+	if err != nil {
+		logger.HaltOnErr(err)
+	}
+
+	idempotencyTTL := 24 * time.Hour
+	if rawTTL := os.Getenv("IDEMPOTENCY_TTL"); rawTTL != "" {
+		idempotencyTTL, err = time.ParseDuration(rawTTL)
+		if err != nil {
+			logger.HaltOnErr(err)
+		}
+	}
+
+	subscriptionInterval := time.Hour
+	if rawInterval := os.Getenv("SUBSCRIPTION_INTERVAL"); rawInterval != "" {
+		subscriptionInterval, err = time.ParseDuration(rawInterval)
+		if err != nil {
+			logger.HaltOnErr(err)
+		}
+	}
+
+	pointsTTL := 365 * 24 * time.Hour
+	if rawTTL := os.Getenv("LOYALTY_POINTS_TTL"); rawTTL != "" {
+		pointsTTL, err = time.ParseDuration(rawTTL)
+		if err != nil {
+			logger.HaltOnErr(err)
+		}
+	}
+
+	recommendationInterval := 6 * time.Hour
+	if rawInterval := os.Getenv("RECOMMENDATION_INTERVAL"); rawInterval != "" {
+		recommendationInterval, err = time.ParseDuration(rawInterval)
+		if err != nil {
+			logger.HaltOnErr(err)
+		}
+	}
+
 	config := ApiConfig{
-		Queries:    database.New(db),
-		SecretJWT:  secretJWT,
-		AdminEmail: adminEmail,
+		DB:             db,
+		Queries:        database.New(db),
+		SecretJWT:      secretJWT,
+		AdminEmail:     adminEmail,
+		Notifier:       notify,
+		Payments:       payments,
+		Storage:        files,
+		IdempotencyTTL: idempotencyTTL,
+		PointsTTL:      pointsTTL,
 	}
 
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("GET /api/items", config.HandlerGetItems)
+	mux.HandleFunc("GET /api/items/{itemID}", config.HandlerGetItem)
+	mux.HandleFunc("GET /api/search", config.HandlerSearchItems)
+	mux.HandleFunc("GET /images/{key...}", config.HandlerServeImage)
 	mux.HandleFunc("GET /api/shopping_cart", config.HandlerGetShoppingCart)
+	mux.HandleFunc("GET /api/shopping_cart/pricing", config.HandlerGetCartPricing)
+	mux.HandleFunc("GET /api/wishlist", config.HandlerGetWishlist)
+	mux.HandleFunc("GET /api/addresses", config.HandlerGetAddresses)
+	mux.HandleFunc("GET /api/delivery/quote", config.HandlerGetDeliveryQuote)
+	mux.HandleFunc("GET /api/delivery/slots", config.HandlerGetDeliverySlots)
+	mux.HandleFunc("GET /api/cart/slot", config.HandlerGetCartSlot)
+	mux.HandleFunc("GET /api/payments/{paymentID}", config.HandlerGetPayment)
+	mux.HandleFunc("GET /api/orders/{orderID}", config.HandlerGetOrder)
+	mux.HandleFunc("GET /api/subscriptions", config.HandlerGetSubscriptions)
+	mux.HandleFunc("GET /api/items/{itemID}/seasons", config.HandlerGetItemSeasons)
+	mux.HandleFunc("GET /api/items/{itemID}/reviews", config.HandlerGetItemReviews)
+	mux.HandleFunc("GET /api/items/{itemID}/recommendations", config.HandlerGetItemRecommendations)
+	mux.HandleFunc("GET /api/recommendations/buy_again", config.HandlerGetBuyAgain)
+	mux.HandleFunc("GET /api/me/points", config.HandlerGetPoints)
+	mux.HandleFunc("GET /api/me/credit", config.HandlerGetStoreCredit)
+	mux.HandleFunc("GET /api/me/referral", config.HandlerGetReferral)
+	mux.HandleFunc("GET /api/preorders", config.HandlerGetPreOrders)
 
 	mux.HandleFunc("POST /api/reg", config.HandlerRegUser)
 	mux.HandleFunc("POST /api/login", config.HandlerLogin)
 	mux.HandleFunc("POST /api/item/{itemID}", config.HandlerGetInCart)
 	mux.HandleFunc("POST /api/refresh", config.HandlerRefresh)
+	mux.HandleFunc("POST /api/wishlist/{itemID}", config.HandlerAddToWishlist)
+	mux.HandleFunc("POST /api/notify/{itemID}", config.HandlerSubscribeToStock)
+	mux.HandleFunc("POST /api/cart/coupon", config.HandlerApplyCoupon)
+	mux.HandleFunc("POST /api/cart/points", config.HandlerRedeemPoints)
+	mux.HandleFunc("POST /api/addresses", config.HandlerCreateAddress)
+	mux.HandleFunc("POST /api/cart/slot/{slotID}", config.HandlerHoldCartSlot)
+	mux.HandleFunc("POST /api/payments", config.HandlerCreatePayment)
+	mux.HandleFunc("POST /api/payments/{paymentID}/capture", config.HandlerCapturePayment)
+	mux.HandleFunc("POST /api/payments/webhook", config.HandlerPaymentWebhook)
+	mux.HandleFunc("POST /api/subscriptions", config.HandlerCreateSubscription)
+	mux.HandleFunc("POST /api/subscriptions/{subscriptionID}/pause", config.HandlerPauseSubscription)
+	mux.HandleFunc("POST /api/subscriptions/{subscriptionID}/resume", config.HandlerResumeSubscription)
+	mux.HandleFunc("POST /api/subscriptions/{subscriptionID}/skip", config.HandlerSkipSubscription)
+	mux.HandleFunc("POST /api/subscriptions/{subscriptionID}/cancel", config.HandlerCancelSubscription)
+	mux.HandleFunc("POST /api/preorders/{itemID}", config.HandlerCreatePreOrder)
+	mux.HandleFunc("POST /api/items/{itemID}/reviews", config.HandlerReviewItem)
+	mux.HandleFunc("POST /api/gift_cards/redeem", config.HandlerRedeemGiftCard)
 
 	mux.HandleFunc("DELETE /api/delete/{itemID}", config.HandlerDeleteFromCart)
+	mux.HandleFunc("DELETE /api/wishlist/{itemID}", config.HandlerDeleteFromWishlist)
+	mux.HandleFunc("DELETE /api/notify/{itemID}", config.HandlerUnsubscribeFromStock)
+	mux.HandleFunc("DELETE /api/cart/coupon", config.HandlerRemoveCoupon)
+	mux.HandleFunc("DELETE /api/cart/points", config.HandlerRemovePoints)
+	mux.HandleFunc("DELETE /api/addresses/{addressID}", config.HandlerDeleteAddress)
+	mux.HandleFunc("DELETE /api/cart/slot", config.HandlerReleaseCartSlot)
+	mux.HandleFunc("DELETE /api/preorders/{itemID}", config.HandlerDeletePreOrder)
+	mux.HandleFunc("DELETE /api/items/{itemID}/reviews", config.HandlerDeleteReview)
 
 	mux.HandleFunc("POST /admin/item", config.HandlerInsertItem)
+	mux.HandleFunc("POST /admin/items/import", config.HandlerImportItems)
+	mux.HandleFunc("GET /admin/items/export", config.HandlerExportItems)
+	mux.HandleFunc("PUT /admin/item/{itemID}/details", config.HandlerUpdateItemDetails)
+	mux.HandleFunc("POST /admin/item/{itemID}/images", config.HandlerUploadItemImage)
+	mux.HandleFunc("DELETE /admin/item/{itemID}/images/{imageID}", config.HandlerDeleteItemImage)
+	mux.HandleFunc("POST /admin/bundles", config.HandlerCreateBundle)
+	mux.HandleFunc("POST /admin/item/{itemID}/batches", config.HandlerReceiveBatch)
+	mux.HandleFunc("GET /admin/batches/expiring", config.HandlerGetExpiringBatches)
+	mux.HandleFunc("GET /admin/item/{itemID}/movements", config.HandlerGetItemMovements)
+	mux.HandleFunc("PUT /admin/item/{itemID}/reorder_threshold", config.HandlerSetReorderThreshold)
+	mux.HandleFunc("GET /admin/item/{itemID}/prices", config.HandlerGetItemPrices)
+	mux.HandleFunc("POST /admin/item/{itemID}/prices", config.HandlerSchedulePrice)
+	mux.HandleFunc("DELETE /admin/item/{itemID}/prices/{priceID}", config.HandlerDeleteScheduledPrice)
+	mux.HandleFunc("POST /admin/item/{itemID}/seasons", config.HandlerCreateItemSeason)
+	mux.HandleFunc("DELETE /admin/item/{itemID}/seasons/{seasonID}", config.HandlerDeleteItemSeason)
+	mux.HandleFunc("GET /admin/item/{itemID}/preorders", config.HandlerGetItemPreOrders)
 	mux.HandleFunc("DELETE /admin/revoke/{tokenID}", config.HandlerRevokeToken)
 
+	mux.HandleFunc("GET /admin/promotions", config.HandlerGetPromotions)
+	mux.HandleFunc("POST /admin/promotions", config.HandlerCreatePromotion)
+	mux.HandleFunc("PUT /admin/promotions/{promotionID}", config.HandlerUpdatePromotion)
+	mux.HandleFunc("DELETE /admin/promotions/{promotionID}", config.HandlerDeletePromotion)
+
+	mux.HandleFunc("GET /admin/coupons", config.HandlerGetCoupons)
+	mux.HandleFunc("POST /admin/coupons", config.HandlerCreateCoupon)
+	mux.HandleFunc("DELETE /admin/coupons/{couponID}", config.HandlerDeleteCoupon)
+
+	mux.HandleFunc("GET /admin/delivery/zones", config.HandlerGetDeliveryZones)
+	mux.HandleFunc("POST /admin/delivery/zones", config.HandlerCreateDeliveryZone)
+	mux.HandleFunc("PUT /admin/delivery/zones/{zoneID}", config.HandlerUpdateDeliveryZone)
+	mux.HandleFunc("DELETE /admin/delivery/zones/{zoneID}", config.HandlerDeleteDeliveryZone)
+	mux.HandleFunc("POST /admin/delivery/slots", config.HandlerCreateDeliverySlot)
+	mux.HandleFunc("DELETE /admin/delivery/slots/{slotID}", config.HandlerDeleteDeliverySlot)
+
+	mux.HandleFunc("POST /admin/payments/{paymentID}/refunds", config.HandlerRefundPayment)
+
+	mux.HandleFunc("GET /admin/suppliers", config.HandlerGetSuppliers)
+	mux.HandleFunc("POST /admin/suppliers", config.HandlerCreateSupplier)
+	mux.HandleFunc("GET /admin/purchase_orders", config.HandlerGetPurchaseOrders)
+	mux.HandleFunc("GET /admin/purchase_orders/{purchaseOrderID}", config.HandlerGetPurchaseOrder)
+	mux.HandleFunc("POST /admin/purchase_orders", config.HandlerCreatePurchaseOrder)
+	mux.HandleFunc("POST /admin/purchase_orders/{purchaseOrderID}/receive", config.HandlerReceivePurchaseOrder)
+	mux.HandleFunc("POST /admin/purchase_orders/{purchaseOrderID}/cancel", config.HandlerCancelPurchaseOrder)
+	mux.HandleFunc("GET /admin/reports/margins", config.HandlerGetMargins)
+
+	mux.HandleFunc("GET /admin/reviews", config.HandlerGetReviews)
+	mux.HandleFunc("POST /admin/reviews/{reviewID}/approve", config.HandlerApproveReview)
+	mux.HandleFunc("POST /admin/reviews/{reviewID}/hide", config.HandlerHideReview)
+
+	mux.HandleFunc("GET /admin/gift_cards", config.HandlerGetGiftCards)
+	mux.HandleFunc("POST /admin/gift_cards", config.HandlerIssueGiftCard)
+	mux.HandleFunc("GET /admin/credit/ledger", config.HandlerGetCreditLedger)
+
+	mux.HandleFunc("GET /admin/referrals", config.HandlerGetReferrals)
+
+	go config.purgeIdempotencyKeys(time.Hour)
+	go config.runSubscriptions(subscriptionInterval)
+	go config.writeOffExpiredBatches(time.Hour)
+	go config.runRecommendations(recommendationInterval)
+	go config.expireLoyaltyPoints(time.Hour)
+
 	server := &http.Server{
 		Addr:    ":8080",
-		Handler: mux,
+		Handler: config.Idempotent(mux),
 	}
 
 	logger.Info("Starting server...")
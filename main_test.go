@@ -0,0 +1,121 @@
+package main
+
+import (
+	"HomeFruits/internal/database"
+	"HomeFruits/internal/jwt"
+	"HomeFruits/internal/notifier"
+	"HomeFruits/internal/payment"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const testSecretJWT = "test-secret"
+
+// newTestConfig connects to the migrated database in TEST_DB_URL, tests that need one are skipped without it.
+func newTestConfig(t *testing.T) *ApiConfig {
+	t.Helper()
+
+	dbUrl := os.Getenv("TEST_DB_URL")
+	if dbUrl == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dbUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	payments, err := payment.NewMockProvider("test-webhook-secret", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ApiConfig{
+		DB:             db,
+		Queries:        database.New(db),
+		SecretJWT:      testSecretJWT,
+		Notifier:       notifier.LogNotifier{},
+		Payments:       payments,
+		IdempotencyTTL: time.Hour,
+		PointsTTL:      time.Hour,
+	}
+}
+
+// newTestUser creates a user and returns it with a bearer token.
+func newTestUser(t *testing.T, cfg *ApiConfig) (database.User, string) {
+	t.Helper()
+
+	user, err := cfg.Queries.CreateNewUser(context.Background(), database.CreateNewUserParams{
+		Email:          uuid.NewString() + "@example.com",
+		HashedPassword: "unused",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := jwt.MakeJWT(user.ID, cfg.SecretJWT, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return user, token
+}
+
+// newTestItem creates an item with quantity units in a single batch.
+func newTestItem(t *testing.T, cfg *ApiConfig, quantity int32) database.Item {
+	t.Helper()
+
+	item, err := cfg.Queries.InsertItem(context.Background(), database.InsertItemParams{
+		Name:     "test item " + uuid.NewString(),
+		Quantity: quantity,
+		Cost:     100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cfg.Queries.CreateItemBatch(context.Background(), database.CreateItemBatchParams{
+		ItemID:     item.ID,
+		ReceivedAt: time.Now(),
+		Quantity:   quantity,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return item
+}
+
+// serveTest calls handler with an authorized request and returns the recorded response.
+func serveTest(t *testing.T, handler http.HandlerFunc, method, target, token string, body any, pathValues map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&payload).Encode(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := httptest.NewRequest(method, target, &payload)
+	r.Header.Set("Authorization", "Bearer "+token)
+	for name, value := range pathValues {
+		r.SetPathValue(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	return w
+}
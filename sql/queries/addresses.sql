@@ -0,0 +1,27 @@
+-- name: GetUserAddresses :many
+SELECT * FROM addresses
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetUserAddress :one
+SELECT * FROM addresses
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateAddress :one
+INSERT INTO addresses(id, user_id, label, street, city, postcode, latitude, longitude, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    NOW()
+)
+RETURNING *;
+
+-- name: DeleteAddress :exec
+DELETE FROM addresses
+WHERE id = $1 AND user_id = $2;
\ No newline at end of file
@@ -0,0 +1,78 @@
+-- name: GetAllCoupons :many
+SELECT * FROM coupons
+ORDER BY created_at DESC;
+
+-- name: GetCouponByCode :one
+SELECT * FROM coupons
+WHERE code = $1;
+
+-- name: CreateCoupon :one
+INSERT INTO coupons(id, code, discount_type, value, max_uses, per_user_limit, min_basket, expires_at, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    NOW()
+)
+RETURNING *;
+
+-- name: DeleteCoupon :exec
+DELETE FROM coupons
+WHERE id = $1;
+
+-- name: CountUserRedemptions :one
+SELECT COUNT(*) FROM coupon_redemptions
+WHERE coupon_id = $1 AND user_id = $2;
+
+-- name: GetCartCoupon :one
+SELECT coupons.* FROM cart_coupons
+JOIN coupons ON coupons.id = cart_coupons.coupon_id
+WHERE cart_coupons.user_id = $1;
+
+-- name: SetCartCoupon :exec
+INSERT INTO cart_coupons(user_id, coupon_id)
+VALUES(
+    $1,
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE SET coupon_id = EXCLUDED.coupon_id;
+
+-- name: DeleteCartCoupon :exec
+DELETE FROM cart_coupons
+WHERE user_id = $1;
+
+-- name: IncrementCouponUsage :one
+UPDATE coupons
+SET times_used = times_used + 1
+WHERE id = $1
+    AND expires_at > NOW()
+    AND (max_uses IS NULL OR times_used < max_uses)
+RETURNING *;
+
+-- name: InsertCouponRedemption :exec
+INSERT INTO coupon_redemptions(id, coupon_id, user_id, redeemed_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW()
+);
+
+-- name: DecrementCouponUsage :exec
+UPDATE coupons
+SET times_used = times_used - 1
+WHERE id = $1 AND times_used > 0;
+
+-- name: DeleteLatestCouponRedemption :exec
+DELETE FROM coupon_redemptions
+WHERE id = (
+    SELECT id FROM coupon_redemptions
+    WHERE coupon_id = $1 AND user_id = $2
+    ORDER BY redeemed_at DESC
+    LIMIT 1
+);
\ No newline at end of file
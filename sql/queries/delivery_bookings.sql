@@ -0,0 +1,15 @@
+-- name: CreateDeliveryBooking :exec
+INSERT INTO delivery_bookings(payment_id, slot_id, user_id, address_id, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+ON CONFLICT (payment_id) DO NOTHING;
+
+-- name: GetSlotBookings :many
+SELECT * FROM delivery_bookings
+WHERE slot_id = $1
+ORDER BY created_at;
\ No newline at end of file
@@ -0,0 +1,48 @@
+-- name: GetDeliverySlots :many
+SELECT * FROM delivery_slots
+WHERE zone_id = $1 AND day = $2
+ORDER BY starts_at;
+
+-- name: CreateDeliverySlot :one
+INSERT INTO delivery_slots(id, zone_id, day, starts_at, ends_at, capacity)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: DeleteDeliverySlot :exec
+DELETE FROM delivery_slots
+WHERE id = $1;
+
+-- name: ReserveDeliverySlot :one
+UPDATE delivery_slots
+SET booked = booked + 1
+WHERE id = $1 AND booked < capacity AND day >= CURRENT_DATE
+RETURNING *;
+
+-- name: ReleaseDeliverySlot :exec
+UPDATE delivery_slots
+SET booked = booked - 1
+WHERE id = $1 AND booked > 0;
+
+-- name: GetCartSlot :one
+SELECT delivery_slots.* FROM cart_slots
+JOIN delivery_slots ON delivery_slots.id = cart_slots.slot_id
+WHERE cart_slots.user_id = $1;
+
+-- name: SetCartSlot :exec
+INSERT INTO cart_slots(user_id, slot_id)
+VALUES(
+    $1,
+    $2
+);
+
+-- name: DeleteCartSlot :one
+DELETE FROM cart_slots
+WHERE user_id = $1
+RETURNING slot_id;
\ No newline at end of file
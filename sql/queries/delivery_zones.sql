@@ -0,0 +1,30 @@
+-- name: GetAllDeliveryZones :many
+SELECT * FROM delivery_zones
+ORDER BY name;
+
+-- name: CreateDeliveryZone :one
+INSERT INTO delivery_zones(id, name, postcodes, polygon, fee, free_threshold, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+)
+RETURNING *;
+
+-- name: UpdateDeliveryZone :one
+UPDATE delivery_zones
+SET name = $2,
+    postcodes = $3,
+    polygon = $4,
+    fee = $5,
+    free_threshold = $6
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteDeliveryZone :exec
+DELETE FROM delivery_zones
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,63 @@
+-- name: CreateGiftCard :one
+INSERT INTO gift_cards(id, code, amount, issued_by, expires_at, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetGiftCards :many
+SELECT * FROM gift_cards
+ORDER BY created_at DESC;
+
+-- name: RedeemGiftCard :one
+UPDATE gift_cards
+SET redeemed_by = $2, redeemed_at = NOW()
+WHERE code = $1
+    AND redeemed_at IS NULL
+    AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: AddStoreCredit :one
+INSERT INTO store_credit_accounts(user_id, balance)
+VALUES(
+    $1,
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET balance = store_credit_accounts.balance + EXCLUDED.balance
+RETURNING balance;
+
+-- name: TakeStoreCredit :execrows
+UPDATE store_credit_accounts
+SET balance = balance - sqlc.arg(amount)::INTEGER
+WHERE user_id = sqlc.arg(user_id) AND balance >= sqlc.arg(amount)::INTEGER;
+
+-- name: GetStoreCreditBalance :one
+SELECT COALESCE((SELECT balance FROM store_credit_accounts WHERE user_id = $1), 0)::INTEGER AS balance;
+
+-- name: InsertCreditMovement :exec
+INSERT INTO credit_movements(id, gift_card_id, user_id, delta, reason, actor_id, reference, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
+);
+
+-- name: GetUserCreditMovements :many
+SELECT * FROM credit_movements
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetCreditLedger :many
+SELECT * FROM credit_movements
+ORDER BY created_at DESC;
\ No newline at end of file
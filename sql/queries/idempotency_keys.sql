@@ -0,0 +1,29 @@
+-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys(scope, key, fingerprint, created_at, expires_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    NOW(),
+    $4
+)
+ON CONFLICT (scope, key) DO NOTHING;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE scope = $1 AND key = $2;
+
+-- name: SaveIdempotentResponse :exec
+UPDATE idempotency_keys
+SET status_code = $3,
+    response_body = $4,
+    response_headers = $5
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE expires_at < NOW();
\ No newline at end of file
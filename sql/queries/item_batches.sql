@@ -0,0 +1,75 @@
+-- name: CreateItemBatch :one
+INSERT INTO item_batches(id, item_id, received_at, best_before, cost_price, quantity)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
+
+-- name: GetOldestBatch :one
+SELECT * FROM item_batches
+WHERE item_id = $1
+    AND quantity > 0
+    AND written_off_at IS NULL
+    AND (best_before IS NULL OR best_before >= CURRENT_DATE)
+ORDER BY received_at, id
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetNewestBatch :one
+SELECT * FROM item_batches
+WHERE item_id = $1
+    AND written_off_at IS NULL
+    AND (best_before IS NULL OR best_before >= CURRENT_DATE)
+ORDER BY received_at DESC, id DESC
+LIMIT 1;
+
+-- name: TakeBatchStock :exec
+UPDATE item_batches
+SET quantity = quantity - sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id);
+
+-- name: AddBatchStock :one
+UPDATE item_batches
+SET quantity = quantity + sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: WriteOffExpiredBatches :many
+UPDATE item_batches
+SET written_off_at = NOW()
+WHERE written_off_at IS NULL
+    AND best_before < CURRENT_DATE
+RETURNING *;
+
+-- name: GetExpiringBatches :many
+SELECT item_batches.id, item_batches.item_id, items.name, item_batches.received_at, item_batches.best_before, item_batches.cost_price, item_batches.quantity FROM item_batches
+JOIN items ON items.id = item_batches.item_id
+WHERE item_batches.written_off_at IS NULL
+    AND item_batches.quantity > 0
+    AND item_batches.best_before <= CURRENT_DATE + sqlc.arg(days)::INTEGER
+ORDER BY item_batches.best_before, item_batches.received_at;
+
+-- name: AddCartBatchAllocation :exec
+INSERT INTO cart_batch_allocations(user_id, item_id, batch_id, quantity)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, item_id, batch_id)
+DO UPDATE SET quantity = cart_batch_allocations.quantity + EXCLUDED.quantity;
+
+-- name: PopCartBatchAllocations :many
+DELETE FROM cart_batch_allocations
+WHERE user_id = $1 AND item_id = $2
+RETURNING batch_id, quantity;
+
+-- name: ClearCartBatchAllocations :exec
+DELETE FROM cart_batch_allocations
+WHERE user_id = $1;
\ No newline at end of file
@@ -0,0 +1,38 @@
+-- name: GetItemDetails :one
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, items.is_bundle,
+    COALESCE(item_details.description, '')::TEXT AS description,
+    COALESCE(item_details.origin_country, '')::TEXT AS origin_country,
+    COALESCE(item_details.is_organic, FALSE)::BOOLEAN AS is_organic,
+    COALESCE(item_details.certifications, '{}')::TEXT[] AS certifications,
+    COALESCE(item_details.nutrition, '{}')::JSONB AS nutrition,
+    COALESCE(item_details.allergens, '')::TEXT AS allergens,
+    COALESCE(item_details.tags, '{}')::TEXT[] AS tags
+FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_details ON item_details.item_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.id = $1;
+
+-- name: UpsertItemDetails :one
+INSERT INTO item_details(item_id, description, origin_country, is_organic, certifications, nutrition, allergens, tags, updated_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    NOW()
+)
+ON CONFLICT (item_id) DO UPDATE SET
+    description = EXCLUDED.description,
+    origin_country = EXCLUDED.origin_country,
+    is_organic = EXCLUDED.is_organic,
+    certifications = EXCLUDED.certifications,
+    nutrition = EXCLUDED.nutrition,
+    allergens = EXCLUDED.allergens,
+    tags = EXCLUDED.tags,
+    updated_at = NOW()
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: CreateItemImage :one
+INSERT INTO item_images(id, item_id, original_key, thumbnail_key, medium_key, content_type, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetAllItemImages :many
+SELECT * FROM item_images
+ORDER BY created_at;
+
+-- name: GetItemImages :many
+SELECT * FROM item_images
+WHERE item_id = $1
+ORDER BY created_at;
+
+-- name: DeleteItemImage :one
+DELETE FROM item_images
+WHERE id = $1 AND item_id = $2
+RETURNING *;
\ No newline at end of file
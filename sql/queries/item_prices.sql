@@ -0,0 +1,26 @@
+-- name: SchedulePrice :one
+INSERT INTO item_prices(id, item_id, price, effective_from, created_by, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetItemPrices :many
+SELECT * FROM item_prices
+WHERE item_id = $1
+ORDER BY effective_from DESC, created_at DESC;
+
+-- name: DeleteScheduledPrice :one
+DELETE FROM item_prices
+WHERE id = $1 AND item_id = $2 AND effective_from > NOW()
+RETURNING *;
+
+-- name: GetCurrentPrice :one
+SELECT COALESCE(item_current_prices.price, items.cost)::INTEGER AS price FROM items
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.id = $1;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- name: SearchItems :many
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category,
+    (ts_rank(item_search.search_vector, websearch_to_tsquery('english', sqlc.arg(query)) || websearch_to_tsquery('russian', sqlc.arg(query)))
+        + word_similarity(sqlc.arg(query), item_search.document))::REAL AS rank,
+    ts_headline(sqlc.arg(config)::REGCONFIG, item_search.document, websearch_to_tsquery(sqlc.arg(config)::REGCONFIG, sqlc.arg(query)),
+        'StartSel=<b>, StopSel=</b>, MaxFragments=2, MaxWords=20, MinWords=5')::TEXT AS snippet
+FROM item_search
+JOIN items ON items.id = item_search.item_id
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE item_search.search_vector @@ (websearch_to_tsquery('english', sqlc.arg(query)) || websearch_to_tsquery('russian', sqlc.arg(query)))
+    OR sqlc.arg(query) <% item_search.document
+ORDER BY rank DESC, items.name
+LIMIT sqlc.arg(max_results);
\ No newline at end of file
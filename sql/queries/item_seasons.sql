@@ -0,0 +1,52 @@
+-- name: CreateItemSeason :one
+INSERT INTO item_seasons(id, item_id, start_month, end_month, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetItemSeasons :many
+SELECT * FROM item_seasons
+WHERE item_id = $1
+ORDER BY start_month;
+
+-- name: GetAllItemSeasons :many
+SELECT * FROM item_seasons
+ORDER BY item_id, start_month;
+
+-- name: DeleteItemSeason :one
+DELETE FROM item_seasons
+WHERE id = $1 AND item_id = $2
+RETURNING *;
+
+-- name: UpsertPreOrder :one
+INSERT INTO pre_orders(user_id, item_id, quantity, created_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT (user_id, item_id) DO UPDATE
+SET quantity = EXCLUDED.quantity
+RETURNING *;
+
+-- name: GetUserPreOrders :many
+SELECT pre_orders.item_id, items.name, pre_orders.quantity, pre_orders.created_at FROM pre_orders
+JOIN items ON items.id = pre_orders.item_id
+WHERE pre_orders.user_id = $1
+ORDER BY pre_orders.created_at;
+
+-- name: GetItemPreOrders :many
+SELECT * FROM pre_orders
+WHERE item_id = $1
+ORDER BY created_at;
+
+-- name: DeletePreOrder :one
+DELETE FROM pre_orders
+WHERE user_id = $1 AND item_id = $2
+RETURNING *;
\ No newline at end of file
@@ -1,21 +1,115 @@
 -- name: GetAllItems :many
-SELECT * FROM items;
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, items.is_bundle,
+    COALESCE(item_ratings.average_rating, 0)::REAL AS average_rating,
+    COALESCE(item_ratings.review_count, 0)::INTEGER AS review_count
+FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+LEFT JOIN item_ratings ON item_ratings.item_id = items.id;
 
 -- name: InsertItem :one
-INSERT INTO items(id, name, quantity, cost)
+INSERT INTO items(id, name, quantity, cost, category, reorder_threshold)
 VALUES(
     gen_random_uuid(),
     $1,
     $2,
-    $3
+    $3,
+    $4,
+    $5
 )
 RETURNING *;
 
 -- name: GetItemById :one
-SELECT name, cost, quantity FROM items
-WHERE id = $1;
+SELECT items.name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, items.is_bundle FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.id = $1;
+
+-- name: AddItemStock :one
+UPDATE items
+SET quantity = quantity + sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id)
+RETURNING name, quantity;
 
--- name: UpdateItemQuantity :exec
+-- name: TakeItemStock :execrows
 UPDATE items
-SET quantity = $1
-WHERE id = $2;
\ No newline at end of file
+SET quantity = quantity - sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id) AND sqlc.arg(amount)::INTEGER > 0 AND quantity >= sqlc.arg(amount)::INTEGER;
+
+-- name: GetSubstituteItem :one
+SELECT items.id, items.name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.quantity FROM items
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE items.category = sqlc.arg(category)
+    AND items.category <> ''
+    AND NOT items.is_bundle
+    AND items.id <> sqlc.arg(id)
+    AND items.quantity >= sqlc.arg(amount)::INTEGER
+    AND (
+        NOT EXISTS (SELECT 1 FROM item_seasons WHERE item_seasons.item_id = items.id)
+        OR EXISTS (
+            SELECT 1 FROM item_seasons
+            WHERE item_seasons.item_id = items.id
+                AND CASE
+                    WHEN item_seasons.start_month <= item_seasons.end_month
+                    THEN EXTRACT(MONTH FROM NOW()) BETWEEN item_seasons.start_month AND item_seasons.end_month
+                    ELSE EXTRACT(MONTH FROM NOW()) >= item_seasons.start_month OR EXTRACT(MONTH FROM NOW()) <= item_seasons.end_month
+                END
+        )
+    )
+ORDER BY ABS(COALESCE(item_current_prices.price, items.cost) - sqlc.arg(target_cost)::INTEGER)
+LIMIT 1;
+
+-- name: CreateBundle :one
+INSERT INTO items(id, name, quantity, cost, category, is_bundle)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    0,
+    $2,
+    $3,
+    TRUE
+)
+RETURNING *;
+
+-- name: AddBundleComponent :exec
+INSERT INTO bundle_components(bundle_id, item_id, quantity)
+VALUES(
+    $1,
+    $2,
+    $3
+);
+
+-- name: GetBundleComponents :many
+SELECT item_id, quantity FROM bundle_components
+WHERE bundle_id = $1;
+
+-- name: GetBundleStock :one
+SELECT quantity FROM bundle_stock
+WHERE bundle_id = $1;
+
+-- name: WriteOffItemStock :exec
+UPDATE items
+SET quantity = quantity - sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id);
+
+-- name: SetReorderThreshold :one
+UPDATE items
+SET reorder_threshold = $2
+WHERE id = $1 AND NOT is_bundle
+RETURNING *;
+
+-- name: GetItemByName :one
+SELECT * FROM items
+WHERE name = $1
+FOR UPDATE;
+
+-- name: UpdateItemDetails :exec
+UPDATE items
+SET category = $2, reorder_threshold = $3
+WHERE id = $1;
+
+-- name: GetCatalog :many
+SELECT items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, items.reorder_threshold FROM items
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+ORDER BY items.name;
\ No newline at end of file
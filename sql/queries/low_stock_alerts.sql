@@ -0,0 +1,19 @@
+-- name: ClaimLowStockAlert :one
+WITH low_item AS (
+    SELECT id, name, quantity, reorder_threshold FROM items
+    WHERE id = $1 AND reorder_threshold > 0 AND quantity <= reorder_threshold
+), claimed AS (
+    INSERT INTO low_stock_alerts(item_id, alerted_at)
+    SELECT id, NOW() FROM low_item
+    ON CONFLICT (item_id) DO NOTHING
+    RETURNING item_id
+)
+SELECT low_item.name, low_item.quantity, low_item.reorder_threshold FROM low_item
+JOIN claimed ON claimed.item_id = low_item.id;
+
+-- name: ClearLowStockAlert :exec
+DELETE FROM low_stock_alerts
+USING items
+WHERE low_stock_alerts.item_id = $1
+    AND items.id = low_stock_alerts.item_id
+    AND items.quantity > items.reorder_threshold;
\ No newline at end of file
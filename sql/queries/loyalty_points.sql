@@ -0,0 +1,76 @@
+-- name: CreatePointLot :one
+INSERT INTO loyalty_point_lots(id, user_id, payment_id, points, remaining, earned_at, expires_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $3,
+    NOW(),
+    $4
+)
+RETURNING *;
+
+-- name: GetOldestPointLot :one
+SELECT * FROM loyalty_point_lots
+WHERE user_id = $1 AND remaining > 0 AND expired_at IS NULL AND expires_at > NOW()
+ORDER BY expires_at
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetPaymentPointLot :one
+SELECT * FROM loyalty_point_lots
+WHERE payment_id = $1
+FOR UPDATE;
+
+-- name: TakePointLot :exec
+UPDATE loyalty_point_lots
+SET remaining = remaining - sqlc.arg(amount)::INTEGER
+WHERE id = sqlc.arg(id);
+
+-- name: ExpirePointLots :many
+UPDATE loyalty_point_lots
+SET expired_at = NOW()
+WHERE expired_at IS NULL AND expires_at <= NOW()
+RETURNING *;
+
+-- name: GetPointBalance :one
+SELECT COALESCE(SUM(remaining), 0)::INTEGER AS balance FROM loyalty_point_lots
+WHERE user_id = $1 AND expired_at IS NULL AND expires_at > NOW();
+
+-- name: InsertPointMovement :exec
+INSERT INTO loyalty_point_movements(id, user_id, delta, reason, reference, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW()
+);
+
+-- name: GetPointMovements :many
+SELECT * FROM loyalty_point_movements
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetRedeemedPoints :one
+SELECT COALESCE(-SUM(delta), 0)::INTEGER AS points FROM loyalty_point_movements
+WHERE user_id = $1 AND reference = $2 AND reason IN ('redeem', 'redeem_reversal');
+
+-- name: SetCartPointRedemption :exec
+INSERT INTO cart_point_redemptions(user_id, points)
+VALUES(
+    $1,
+    $2
+)
+ON CONFLICT (user_id) DO UPDATE
+SET points = EXCLUDED.points;
+
+-- name: GetCartPointRedemption :one
+SELECT points FROM cart_point_redemptions
+WHERE user_id = $1;
+
+-- name: DeleteCartPointRedemption :exec
+DELETE FROM cart_point_redemptions
+WHERE user_id = $1;
\ No newline at end of file
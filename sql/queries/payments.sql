@@ -0,0 +1,64 @@
+-- name: CreatePayment :one
+INSERT INTO payments(id, user_id, amount, store_credit, address_id, delivery_fee, slot_id, status, created_at, updated_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    'created',
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: SetPaymentIntent :one
+UPDATE payments
+SET provider_intent_id = $2,
+    status = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetPaymentCoupon :one
+UPDATE payments
+SET coupon_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserPayment :one
+SELECT * FROM payments
+WHERE id = $1 AND user_id = $2;
+
+-- name: UpdatePendingPaymentStatus :execrows
+UPDATE payments
+SET status = $2,
+    updated_at = NOW()
+WHERE provider_intent_id = $1
+    AND status IN ('requires_capture', 'requires_action', 'processing');
+
+-- name: InsertPaymentEvent :execrows
+INSERT INTO payment_events(id, type, provider_intent_id, received_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: GetPaymentByIntent :one
+SELECT * FROM payments
+WHERE provider_intent_id = $1;
+
+-- name: SnapshotPaymentItems :exec
+INSERT INTO payment_items(payment_id, item_id, quantity, cost)
+SELECT sqlc.arg(payment_id), item_id, SUM(quantity), SUM(cost) FROM shopping_cart
+WHERE user_id = sqlc.arg(user_id)
+GROUP BY item_id;
+
+-- name: GetPaymentItems :many
+SELECT * FROM payment_items
+WHERE payment_id = $1;
\ No newline at end of file
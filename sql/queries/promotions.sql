@@ -0,0 +1,44 @@
+-- name: GetAllPromotions :many
+SELECT * FROM promotions
+ORDER BY starts_at DESC;
+
+-- name: GetActivePromotions :many
+SELECT * FROM promotions
+WHERE starts_at <= NOW() AND ends_at > NOW();
+
+-- name: CreatePromotion :one
+INSERT INTO promotions(id, name, rule_type, item_id, category, percent, amount, buy_quantity, free_quantity, starts_at, ends_at, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    NOW()
+)
+RETURNING *;
+
+-- name: UpdatePromotion :one
+UPDATE promotions
+SET name = $2,
+    rule_type = $3,
+    item_id = $4,
+    category = $5,
+    percent = $6,
+    amount = $7,
+    buy_quantity = $8,
+    free_quantity = $9,
+    starts_at = $10,
+    ends_at = $11
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePromotion :exec
+DELETE FROM promotions
+WHERE id = $1;
\ No newline at end of file
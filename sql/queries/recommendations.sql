@@ -0,0 +1,40 @@
+-- name: DeleteItemRecommendations :exec
+DELETE FROM item_recommendations;
+
+-- name: RefreshItemRecommendations :exec
+INSERT INTO item_recommendations(item_id, recommended_item_id, score)
+SELECT item_id, recommended_item_id, score FROM (
+    SELECT bought.item_id, also_bought.item_id AS recommended_item_id, COUNT(DISTINCT bought.user_id)::INTEGER AS score,
+        ROW_NUMBER() OVER (PARTITION BY bought.item_id ORDER BY COUNT(DISTINCT bought.user_id) DESC, also_bought.item_id) AS position
+    FROM purchases AS bought
+    JOIN purchases AS also_bought ON also_bought.user_id = bought.user_id AND also_bought.item_id <> bought.item_id
+    GROUP BY bought.item_id, also_bought.item_id
+) AS pairs
+WHERE position <= sqlc.arg(per_item)::INTEGER;
+
+-- name: DeleteBuyAgainRecommendations :exec
+DELETE FROM buy_again_recommendations;
+
+-- name: RefreshBuyAgainRecommendations :exec
+INSERT INTO buy_again_recommendations(user_id, item_id, times_bought, last_bought_at)
+SELECT user_id, item_id, COUNT(*)::INTEGER, MAX(bought_at) FROM purchases
+GROUP BY user_id, item_id;
+
+-- name: GetItemRecommendations :many
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, item_recommendations.score FROM item_recommendations
+JOIN items ON items.id = item_recommendations.recommended_item_id
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE item_recommendations.item_id = sqlc.arg(item_id)
+    AND COALESCE(bundle_stock.quantity, items.quantity) > 0
+ORDER BY item_recommendations.score DESC, items.name
+LIMIT sqlc.arg(max_results);
+
+-- name: GetBuyAgainRecommendations :many
+SELECT items.id, items.name, COALESCE(bundle_stock.quantity, items.quantity)::INTEGER AS quantity, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category, buy_again_recommendations.times_bought, buy_again_recommendations.last_bought_at FROM buy_again_recommendations
+JOIN items ON items.id = buy_again_recommendations.item_id
+LEFT JOIN bundle_stock ON bundle_stock.bundle_id = items.id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE buy_again_recommendations.user_id = sqlc.arg(user_id)
+ORDER BY buy_again_recommendations.times_bought DESC, buy_again_recommendations.last_bought_at DESC
+LIMIT sqlc.arg(max_results);
\ No newline at end of file
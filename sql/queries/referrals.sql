@@ -0,0 +1,63 @@
+-- name: CreateReferralCode :exec
+INSERT INTO referral_codes(user_id, code, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id) DO NOTHING;
+
+-- name: GetReferralCode :one
+SELECT code FROM referral_codes
+WHERE user_id = $1;
+
+-- name: GetReferrerByCode :one
+SELECT referral_codes.user_id, users.email FROM referral_codes
+JOIN users ON users.id = referral_codes.user_id
+WHERE referral_codes.code = $1;
+
+-- name: CreateReferral :one
+INSERT INTO referrals(id, referrer_id, referee_id, status, rejection_reason, created_at, completed_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    NOW(),
+    CASE WHEN $3 = 'pending' THEN NULL ELSE NOW() END
+)
+RETURNING *;
+
+-- name: GetPendingReferral :one
+SELECT * FROM referrals
+WHERE referee_id = $1 AND status = 'pending'
+FOR UPDATE;
+
+-- name: GetRewardedReferralByPayment :one
+SELECT * FROM referrals
+WHERE payment_id = $1 AND status = 'rewarded'
+FOR UPDATE;
+
+-- name: CompleteReferral :exec
+UPDATE referrals
+SET status = $2, rejection_reason = $3, payment_id = $4, completed_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserReferrals :many
+SELECT * FROM referrals
+WHERE referrer_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetReferrals :many
+SELECT * FROM referrals
+ORDER BY created_at DESC;
+
+-- name: SharesAddress :one
+SELECT EXISTS(
+    SELECT 1 FROM addresses AS referrer_addresses
+    JOIN addresses AS referee_addresses
+    ON LOWER(TRIM(referrer_addresses.street)) = LOWER(TRIM(referee_addresses.street))
+    AND LOWER(REPLACE(referrer_addresses.postcode, ' ', '')) = LOWER(REPLACE(referee_addresses.postcode, ' ', ''))
+    WHERE referrer_addresses.user_id = sqlc.arg(referrer_id) AND referee_addresses.user_id = sqlc.arg(referee_id)
+);
\ No newline at end of file
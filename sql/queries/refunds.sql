@@ -0,0 +1,65 @@
+-- name: LockPayment :one
+SELECT * FROM payments
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetRefundedAmount :one
+SELECT COALESCE(SUM(amount), 0)::INTEGER FROM refunds
+WHERE payment_id = $1 AND status <> 'failed';
+
+-- name: GetProviderRefundedAmount :one
+SELECT COALESCE(SUM(amount - store_credit), 0)::INTEGER FROM refunds
+WHERE payment_id = $1 AND status <> 'failed';
+
+-- name: CreateRefund :one
+INSERT INTO refunds(id, payment_id, amount, store_credit, status, reason, created_at, updated_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: SetRefundProvider :one
+UPDATE refunds
+SET provider_refund_id = $2,
+    status = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: AddRefundItem :exec
+INSERT INTO refund_items(refund_id, item_id, quantity, restocked)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+);
+
+-- name: GetPaymentRefunds :many
+SELECT * FROM refunds
+WHERE payment_id = $1
+ORDER BY created_at;
+
+-- name: UpdateRefundStatus :execrows
+UPDATE refunds
+SET status = $2,
+    updated_at = NOW()
+WHERE provider_refund_id = $1 AND status = 'processing';
+
+-- name: GetOrderLines :many
+SELECT payment_items.item_id, items.name, payment_items.quantity, payment_items.cost, COALESCE((
+    SELECT SUM(refund_items.quantity) FROM refund_items
+    JOIN refunds ON refunds.id = refund_items.refund_id
+    WHERE refunds.payment_id = payment_items.payment_id AND refund_items.item_id = payment_items.item_id AND refunds.status <> 'failed'
+), 0)::INTEGER AS refunded_quantity
+FROM payment_items
+JOIN items ON items.id = payment_items.item_id
+WHERE payment_items.payment_id = $1
+ORDER BY items.name;
\ No newline at end of file
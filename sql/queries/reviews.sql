@@ -0,0 +1,47 @@
+-- name: UpsertReview :one
+INSERT INTO reviews(id, user_id, item_id, rating, body, status, created_at, updated_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    'pending',
+    NOW(),
+    NOW()
+)
+ON CONFLICT (user_id, item_id) DO UPDATE SET
+    rating = EXCLUDED.rating,
+    body = EXCLUDED.body,
+    status = 'pending',
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetItemReviews :many
+SELECT * FROM reviews
+WHERE item_id = $1 AND status = 'approved'
+ORDER BY updated_at DESC;
+
+-- name: GetReviewsByStatus :many
+SELECT * FROM reviews
+WHERE status = $1
+ORDER BY updated_at;
+
+-- name: SetReviewStatus :one
+UPDATE reviews
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteReview :one
+DELETE FROM reviews
+WHERE user_id = $1 AND item_id = $2
+RETURNING *;
+
+-- name: HasPurchasedItem :one
+SELECT EXISTS (
+    SELECT 1 FROM purchases
+    WHERE purchases.user_id = sqlc.arg(user_id)
+        AND (purchases.item_id = sqlc.arg(item_id)
+            OR purchases.item_id IN (SELECT bundle_id FROM bundle_components WHERE item_id = sqlc.arg(item_id)))
+)::BOOLEAN AS purchased;
\ No newline at end of file
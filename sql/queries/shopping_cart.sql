@@ -2,6 +2,11 @@
 SELECT * FROM shopping_cart
 WHERE user_id = $1;
 
+-- name: GetShoppingCartForPricing :many
+SELECT shopping_cart.item_id, shopping_cart.quantity, shopping_cart.cost, items.category FROM shopping_cart
+JOIN items ON items.id = shopping_cart.item_id
+WHERE shopping_cart.user_id = $1;
+
 -- name: AddItemInCart :exec
 INSERT INTO shopping_cart(item_id, user_id, quantity, cost, item_name)
 VALUES (
@@ -15,4 +20,8 @@ VALUES (
 -- name: DeleteFromCart :one
 DELETE FROM shopping_cart
 WHERE item_id = $1 AND user_id = $2
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ClearShoppingCart :exec
+DELETE FROM shopping_cart
+WHERE user_id = $1;
\ No newline at end of file
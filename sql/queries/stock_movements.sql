@@ -0,0 +1,20 @@
+-- name: InsertStockMovement :exec
+INSERT INTO stock_movements(id, item_id, delta, reason, actor_id, reference, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    NOW()
+);
+
+-- name: GetItemMovements :many
+SELECT * FROM stock_movements
+WHERE item_id = $1
+ORDER BY created_at DESC;
+
+-- name: GetLedgerQuantity :one
+SELECT COALESCE(SUM(delta), 0)::INTEGER AS quantity FROM stock_movements
+WHERE item_id = $1;
\ No newline at end of file
@@ -0,0 +1,84 @@
+-- name: CreateSubscription :one
+INSERT INTO subscriptions(id, user_id, cadence, next_run_at, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING *;
+
+-- name: AddSubscriptionItem :exec
+INSERT INTO subscription_items(subscription_id, item_id, quantity)
+VALUES(
+    $1,
+    $2,
+    $3
+);
+
+-- name: GetUserSubscriptions :many
+SELECT * FROM subscriptions
+WHERE user_id = $1 AND status <> 'cancelled'
+ORDER BY created_at;
+
+-- name: GetSubscriptionItems :many
+SELECT subscription_items.item_id, subscription_items.quantity, items.name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost, items.category FROM subscription_items
+JOIN items ON items.id = subscription_items.item_id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE subscription_items.subscription_id = $1;
+
+-- name: SetSubscriptionStatus :one
+UPDATE subscriptions
+SET status = $3
+WHERE id = $1 AND user_id = $2 AND status <> 'cancelled'
+RETURNING *;
+
+-- name: SkipNextDelivery :one
+UPDATE subscriptions
+SET skip_next = TRUE
+WHERE id = $1 AND user_id = $2 AND status = 'active'
+RETURNING *;
+
+-- name: ClaimDueSubscription :one
+SELECT * FROM subscriptions
+WHERE status = 'active' AND next_run_at <= NOW()
+ORDER BY next_run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceSubscription :exec
+UPDATE subscriptions
+SET next_run_at = $2,
+    skip_next = FALSE
+WHERE id = $1;
+
+-- name: ReserveBoxLine :exec
+INSERT INTO box_reservations(user_id, item_id, subscription_id, release_at)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4
+)
+ON CONFLICT (user_id, item_id)
+DO UPDATE SET subscription_id = EXCLUDED.subscription_id, release_at = GREATEST(box_reservations.release_at, EXCLUDED.release_at);
+
+-- name: ClaimExpiredBoxReservation :one
+DELETE FROM box_reservations
+WHERE (user_id, item_id) = (
+    SELECT user_id, item_id FROM box_reservations
+    WHERE release_at <= NOW()
+    ORDER BY release_at
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: DeleteBoxReservation :exec
+DELETE FROM box_reservations
+WHERE user_id = $1 AND item_id = $2;
+
+-- name: ClearBoxReservations :exec
+DELETE FROM box_reservations
+WHERE user_id = $1;
\ No newline at end of file
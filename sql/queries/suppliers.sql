@@ -0,0 +1,72 @@
+-- name: CreateSupplier :one
+INSERT INTO suppliers(id, name, email, phone, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetSuppliers :many
+SELECT * FROM suppliers
+ORDER BY name;
+
+-- name: CreatePurchaseOrder :one
+INSERT INTO purchase_orders(id, supplier_id, status, expected_at, created_at)
+VALUES(
+    gen_random_uuid(),
+    $1,
+    'open',
+    $2,
+    NOW()
+)
+RETURNING *;
+
+-- name: AddPurchaseOrderLine :exec
+INSERT INTO purchase_order_lines(purchase_order_id, item_id, quantity, unit_cost, best_before)
+VALUES(
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
+
+-- name: GetPurchaseOrders :many
+SELECT * FROM purchase_orders
+ORDER BY expected_at, created_at;
+
+-- name: GetPurchaseOrder :one
+SELECT * FROM purchase_orders
+WHERE id = $1;
+
+-- name: GetPurchaseOrderLines :many
+SELECT * FROM purchase_order_lines
+WHERE purchase_order_id = $1;
+
+-- name: ReceivePurchaseOrder :one
+UPDATE purchase_orders
+SET status = 'received', received_at = NOW()
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: CancelPurchaseOrder :one
+UPDATE purchase_orders
+SET status = 'cancelled'
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: GetItemMargins :many
+SELECT items.id, items.name, suppliers.id AS supplier_id, suppliers.name AS supplier_name, COALESCE(item_current_prices.price, items.cost)::INTEGER AS cost,
+    SUM(purchase_order_lines.quantity)::INTEGER AS received_quantity,
+    (SUM(purchase_order_lines.quantity * purchase_order_lines.unit_cost) / SUM(purchase_order_lines.quantity))::INTEGER AS average_unit_cost
+FROM purchase_order_lines
+JOIN purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id
+JOIN suppliers ON suppliers.id = purchase_orders.supplier_id
+JOIN items ON items.id = purchase_order_lines.item_id
+LEFT JOIN item_current_prices ON item_current_prices.item_id = items.id
+WHERE purchase_orders.status = 'received'
+GROUP BY items.id, suppliers.id, item_current_prices.price
+ORDER BY items.name, suppliers.name;
\ No newline at end of file
@@ -0,0 +1,40 @@
+-- name: GetWishlist :many
+SELECT items.id, items.name, items.quantity, items.cost, wishlist.created_at FROM wishlist
+JOIN items ON items.id = wishlist.item_id
+WHERE wishlist.user_id = $1
+ORDER BY wishlist.created_at DESC;
+
+-- name: AddToWishlist :exec
+INSERT INTO wishlist(user_id, item_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, item_id) DO NOTHING;
+
+-- name: DeleteFromWishlist :exec
+DELETE FROM wishlist
+WHERE user_id = $1 AND item_id = $2;
+
+-- name: SubscribeToStock :exec
+INSERT INTO stock_subscriptions(user_id, item_id, created_at)
+VALUES(
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (user_id, item_id) DO NOTHING;
+
+-- name: UnsubscribeFromStock :exec
+DELETE FROM stock_subscriptions
+WHERE user_id = $1 AND item_id = $2;
+
+-- name: PopStockSubscribers :many
+WITH deleted AS (
+    DELETE FROM stock_subscriptions
+    WHERE item_id = $1
+    RETURNING user_id
+)
+SELECT users.id, users.email FROM deleted
+JOIN users ON users.id = deleted.user_id;
\ No newline at end of file
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE wishlist(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id)
+);
+
+CREATE TABLE stock_subscriptions(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id)
+);
+
+-- +goose Down
+DROP TABLE stock_subscriptions;
+DROP TABLE wishlist;
\ No newline at end of file
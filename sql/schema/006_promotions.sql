@@ -0,0 +1,22 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN category TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE promotions(
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    rule_type TEXT NOT NULL,
+    item_id UUID REFERENCES items (id) ON DELETE CASCADE,
+    category TEXT NOT NULL DEFAULT '',
+    percent INTEGER NOT NULL DEFAULT 0,
+    amount INTEGER NOT NULL DEFAULT 0,
+    buy_quantity INTEGER NOT NULL DEFAULT 0,
+    free_quantity INTEGER NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (rule_type IN ('percentage', 'fixed', 'buy_x_get_y', 'category'))
+);
+
+-- +goose Down
+DROP TABLE promotions;
+ALTER TABLE items DROP COLUMN category;
\ No newline at end of file
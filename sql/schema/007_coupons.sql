@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE coupons(
+    id UUID PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    discount_type TEXT NOT NULL,
+    value INTEGER NOT NULL,
+    max_uses INTEGER,
+    per_user_limit INTEGER NOT NULL DEFAULT 1,
+    min_basket INTEGER NOT NULL DEFAULT 0,
+    times_used INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (discount_type IN ('percentage', 'fixed')),
+    CHECK (max_uses IS NULL OR times_used <= max_uses)
+);
+
+CREATE TABLE coupon_redemptions(
+    id UUID PRIMARY KEY,
+    coupon_id UUID NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    redeemed_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE cart_coupons(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    coupon_id UUID NOT NULL REFERENCES coupons (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE cart_coupons;
+DROP TABLE coupon_redemptions;
+DROP TABLE coupons;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE addresses(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    label TEXT NOT NULL,
+    street TEXT NOT NULL,
+    city TEXT NOT NULL,
+    postcode TEXT NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE delivery_zones(
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    postcodes TEXT[] NOT NULL DEFAULT '{}',
+    polygon JSONB NOT NULL DEFAULT '[]',
+    fee INTEGER NOT NULL,
+    free_threshold INTEGER,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE delivery_zones;
+DROP TABLE addresses;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE delivery_slots(
+    id UUID PRIMARY KEY,
+    zone_id UUID NOT NULL REFERENCES delivery_zones (id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    starts_at TIME NOT NULL,
+    ends_at TIME NOT NULL,
+    capacity INTEGER NOT NULL,
+    booked INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (zone_id, day, starts_at),
+    CHECK (booked >= 0 AND booked <= capacity)
+);
+
+CREATE TABLE cart_slots(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    slot_id UUID NOT NULL REFERENCES delivery_slots (id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE cart_slots;
+DROP TABLE delivery_slots;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- +goose Up
+CREATE TABLE payments(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider_intent_id TEXT UNIQUE,
+    amount INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    store_credit INTEGER NOT NULL DEFAULT 0,
+    address_id UUID REFERENCES addresses (id) ON DELETE SET NULL,
+    delivery_fee INTEGER NOT NULL DEFAULT 0,
+    slot_id UUID REFERENCES delivery_slots (id) ON DELETE SET NULL,
+    coupon_id UUID REFERENCES coupons (id) ON DELETE SET NULL
+);
+
+-- payment_items is what the cart held when the payment was created, cost is the line total at cart prices.
+CREATE TABLE payment_items(
+    payment_id UUID NOT NULL REFERENCES payments (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id),
+    quantity INTEGER NOT NULL,
+    cost INTEGER NOT NULL,
+    PRIMARY KEY (payment_id, item_id)
+);
+
+-- The slot held by the cart moves to the payment when it is created. Once the payment
+-- succeeds the slot is booked for delivery, if it fails the slot is released.
+CREATE TABLE delivery_bookings(
+    payment_id UUID PRIMARY KEY REFERENCES payments (id) ON DELETE CASCADE,
+    slot_id UUID NOT NULL REFERENCES delivery_slots (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    address_id UUID REFERENCES addresses (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX delivery_bookings_slot_id_idx ON delivery_bookings (slot_id);
+
+CREATE TABLE payment_events(
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    provider_intent_id TEXT NOT NULL,
+    received_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE payment_events;
+DROP TABLE delivery_bookings;
+DROP TABLE payment_items;
+DROP TABLE payments;
\ No newline at end of file
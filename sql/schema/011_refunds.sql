@@ -0,0 +1,26 @@
+-- +goose Up
+-- A refund row is written before the provider is called and gets the provider id afterwards.
+-- store_credit is the part of amount given back as store credit, the provider refunds the rest.
+CREATE TABLE refunds(
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments (id) ON DELETE CASCADE,
+    provider_refund_id TEXT UNIQUE,
+    amount INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    store_credit INTEGER NOT NULL DEFAULT 0
+);
+
+-- refund_items are the order lines a refund covers, restocked when the returned units went back on sale.
+CREATE TABLE refund_items(
+    refund_id UUID NOT NULL REFERENCES refunds (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id),
+    quantity INTEGER NOT NULL,
+    restocked BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- +goose Down
+DROP TABLE refund_items;
+DROP TABLE refunds;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE idempotency_keys(
+    scope TEXT NOT NULL,
+    key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status_code INTEGER,
+    response_body BYTEA,
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE subscriptions(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    cadence TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    skip_next BOOLEAN NOT NULL DEFAULT FALSE,
+    next_run_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (cadence IN ('weekly', 'biweekly', 'monthly')),
+    CHECK (status IN ('active', 'paused', 'cancelled'))
+);
+
+CREATE TABLE subscription_items(
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (subscription_id, item_id)
+);
+
+-- A filled box waits in the shopping cart until release_at, box lines still unpaid then go back to stock.
+CREATE TABLE box_reservations(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    release_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id)
+);
+
+CREATE INDEX box_reservations_release_at_idx ON box_reservations (release_at);
+
+-- +goose Down
+DROP TABLE box_reservations;
+DROP TABLE subscription_items;
+DROP TABLE subscriptions;
\ No newline at end of file
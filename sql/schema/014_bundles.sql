@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN is_bundle BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE bundle_components(
+    bundle_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id),
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (bundle_id, item_id),
+    CHECK (quantity > 0)
+);
+
+CREATE VIEW bundle_stock AS
+SELECT bundle_components.bundle_id, MIN(items.quantity / bundle_components.quantity)::INTEGER AS quantity
+FROM bundle_components
+JOIN items ON items.id = bundle_components.item_id
+GROUP BY bundle_components.bundle_id;
+
+-- +goose Down
+DROP VIEW bundle_stock;
+DROP TABLE bundle_components;
+ALTER TABLE items DROP COLUMN is_bundle;
\ No newline at end of file
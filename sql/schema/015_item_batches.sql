@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE item_batches(
+    id UUID PRIMARY KEY,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    received_at TIMESTAMP NOT NULL,
+    best_before DATE,
+    cost_price INTEGER NOT NULL,
+    quantity INTEGER NOT NULL,
+    written_off_at TIMESTAMP DEFAULT NULL,
+    CHECK (quantity >= 0)
+);
+
+CREATE INDEX item_batches_item_id_idx ON item_batches (item_id, received_at);
+
+CREATE TABLE cart_batch_allocations(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    batch_id UUID NOT NULL REFERENCES item_batches (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    PRIMARY KEY (user_id, item_id, batch_id)
+);
+
+INSERT INTO item_batches(id, item_id, received_at, best_before, cost_price, quantity)
+SELECT gen_random_uuid(), id, NOW(), NULL, 0, quantity FROM items
+WHERE quantity > 0 AND NOT is_bundle;
+
+-- +goose Down
+DROP TABLE cart_batch_allocations;
+DROP TABLE item_batches;
\ No newline at end of file
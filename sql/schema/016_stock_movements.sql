@@ -0,0 +1,45 @@
+-- +goose Up
+CREATE TABLE stock_movements(
+    id UUID PRIMARY KEY,
+    item_id UUID NOT NULL REFERENCES items (id),
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    actor_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX stock_movements_item_id_idx ON stock_movements (item_id, created_at);
+
+-- +goose StatementBegin
+-- Deleting a user nulls actor_id through the foreign key, that is the only change allowed.
+CREATE FUNCTION reject_stock_movement_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.item_id = OLD.item_id
+        AND NEW.delta = OLD.delta
+        AND NEW.reason = OLD.reason
+        AND NEW.reference = OLD.reference
+        AND NEW.created_at = OLD.created_at
+        AND (NEW.actor_id IS NULL OR NEW.actor_id = OLD.actor_id)
+    THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER stock_movements_append_only
+BEFORE UPDATE OR DELETE ON stock_movements
+FOR EACH ROW EXECUTE FUNCTION reject_stock_movement_change();
+
+INSERT INTO stock_movements(id, item_id, delta, reason, reference, created_at)
+SELECT gen_random_uuid(), id, quantity, 'opening_balance', '', NOW() FROM items
+WHERE quantity <> 0 AND NOT is_bundle;
+
+-- +goose Down
+DROP TABLE stock_movements;
+DROP FUNCTION reject_stock_movement_change;
\ No newline at end of file
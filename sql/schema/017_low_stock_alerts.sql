@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE items ADD COLUMN reorder_threshold INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE low_stock_alerts(
+    item_id UUID PRIMARY KEY REFERENCES items (id) ON DELETE CASCADE,
+    alerted_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE low_stock_alerts;
+ALTER TABLE items DROP COLUMN reorder_threshold;
\ No newline at end of file
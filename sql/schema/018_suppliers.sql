@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE suppliers(
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE,
+    email TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE purchase_orders(
+    id UUID PRIMARY KEY,
+    supplier_id UUID NOT NULL REFERENCES suppliers (id),
+    status TEXT NOT NULL DEFAULT 'open',
+    expected_at DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE TABLE purchase_order_lines(
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id),
+    quantity INTEGER NOT NULL,
+    unit_cost INTEGER NOT NULL,
+    best_before DATE,
+    PRIMARY KEY (purchase_order_id, item_id),
+    CHECK (quantity > 0),
+    CHECK (unit_cost >= 0)
+);
+
+-- +goose Down
+DROP TABLE purchase_order_lines;
+DROP TABLE purchase_orders;
+DROP TABLE suppliers;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE item_images(
+    id UUID PRIMARY KEY,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    original_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    medium_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX item_images_item_id_idx ON item_images (item_id, created_at);
+
+-- +goose Down
+DROP TABLE item_images;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE item_details(
+    item_id UUID PRIMARY KEY REFERENCES items (id) ON DELETE CASCADE,
+    description TEXT NOT NULL DEFAULT '',
+    origin_country TEXT NOT NULL DEFAULT '',
+    is_organic BOOLEAN NOT NULL DEFAULT FALSE,
+    certifications TEXT[] NOT NULL DEFAULT '{}',
+    nutrition JSONB NOT NULL DEFAULT '{}',
+    allergens TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL,
+    tags TEXT[] NOT NULL DEFAULT '{}'
+);
+
+-- +goose Down
+DROP TABLE item_details;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE item_prices(
+    id UUID PRIMARY KEY,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    price INTEGER NOT NULL,
+    effective_from TIMESTAMP NOT NULL,
+    created_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (price >= 0)
+);
+
+CREATE INDEX item_prices_item_id_idx ON item_prices (item_id, effective_from);
+
+CREATE VIEW item_current_prices AS
+SELECT DISTINCT ON (item_id) item_id, price FROM item_prices
+WHERE effective_from <= NOW()
+ORDER BY item_id, effective_from DESC, created_at DESC;
+
+INSERT INTO item_prices(id, item_id, price, effective_from, created_at)
+SELECT gen_random_uuid(), id, cost, NOW(), NOW() FROM items;
+
+-- +goose Down
+DROP VIEW item_current_prices;
+DROP TABLE item_prices;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE item_seasons(
+    id UUID PRIMARY KEY,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    start_month INTEGER NOT NULL,
+    end_month INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (start_month BETWEEN 1 AND 12 AND end_month BETWEEN 1 AND 12)
+);
+
+CREATE INDEX item_seasons_item_id_idx ON item_seasons (item_id);
+
+CREATE TABLE pre_orders(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id),
+    CHECK (quantity > 0)
+);
+
+-- +goose Down
+DROP TABLE pre_orders;
+DROP TABLE item_seasons;
\ No newline at end of file
@@ -0,0 +1,69 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- item_search keeps one searchable document per item, built from its name, tags and description.
+-- Both English and Russian stems go into the vector so either language finds the item.
+CREATE TABLE item_search(
+    item_id UUID PRIMARY KEY REFERENCES items (id) ON DELETE CASCADE,
+    document TEXT NOT NULL,
+    search_vector TSVECTOR NOT NULL
+);
+
+CREATE INDEX item_search_vector_idx ON item_search USING GIN (search_vector);
+CREATE INDEX item_search_document_trgm_idx ON item_search USING GIN (document gin_trgm_ops);
+
+-- +goose StatementBegin
+CREATE FUNCTION refresh_item_search(target UUID) RETURNS VOID AS $$
+    INSERT INTO item_search(item_id, document, search_vector)
+    SELECT items.id,
+        concat_ws(' ', items.name, array_to_string(COALESCE(item_details.tags, '{}'), ' '), item_details.description),
+        setweight(to_tsvector('english', items.name), 'A') ||
+        setweight(to_tsvector('russian', items.name), 'A') ||
+        setweight(to_tsvector('english', array_to_string(COALESCE(item_details.tags, '{}'), ' ')), 'B') ||
+        setweight(to_tsvector('russian', array_to_string(COALESCE(item_details.tags, '{}'), ' ')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(item_details.description, '')), 'C') ||
+        setweight(to_tsvector('russian', COALESCE(item_details.description, '')), 'C')
+    FROM items
+    LEFT JOIN item_details ON item_details.item_id = items.id
+    WHERE items.id = target
+    ON CONFLICT (item_id) DO UPDATE SET
+        document = EXCLUDED.document,
+        search_vector = EXCLUDED.search_vector;
+$$ LANGUAGE SQL;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION refresh_item_search_from_items() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_item_search(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION refresh_item_search_from_details() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_item_search(NEW.item_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER items_refresh_search
+AFTER INSERT OR UPDATE OF name ON items
+FOR EACH ROW EXECUTE FUNCTION refresh_item_search_from_items();
+
+CREATE TRIGGER item_details_refresh_search
+AFTER INSERT OR UPDATE ON item_details
+FOR EACH ROW EXECUTE FUNCTION refresh_item_search_from_details();
+
+SELECT refresh_item_search(id) FROM items;
+
+-- +goose Down
+DROP TRIGGER item_details_refresh_search ON item_details;
+DROP TRIGGER items_refresh_search ON items;
+DROP FUNCTION refresh_item_search_from_details;
+DROP FUNCTION refresh_item_search_from_items;
+DROP FUNCTION refresh_item_search;
+DROP TABLE item_search;
\ No newline at end of file
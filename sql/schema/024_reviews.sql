@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE reviews(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, item_id),
+    CHECK (rating BETWEEN 1 AND 5),
+    CHECK (status IN ('pending', 'approved', 'hidden'))
+);
+
+CREATE INDEX reviews_item_id_idx ON reviews (item_id, status);
+
+CREATE VIEW item_ratings AS
+SELECT item_id, AVG(rating)::REAL AS average_rating, COUNT(*)::INTEGER AS review_count FROM reviews
+WHERE status = 'approved'
+GROUP BY item_id;
+
+-- +goose Down
+DROP VIEW item_ratings;
+DROP TABLE reviews;
\ No newline at end of file
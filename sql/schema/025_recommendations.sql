@@ -0,0 +1,27 @@
+-- +goose Up
+-- purchases lists what customers bought: the lines of every succeeded payment.
+CREATE VIEW purchases AS
+SELECT payments.user_id, payment_items.item_id, payment_items.quantity, payments.created_at AS bought_at
+FROM payment_items
+JOIN payments ON payments.id = payment_items.payment_id
+WHERE payments.status = 'succeeded';
+
+CREATE TABLE item_recommendations(
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    recommended_item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    score INTEGER NOT NULL,
+    PRIMARY KEY (item_id, recommended_item_id)
+);
+
+CREATE TABLE buy_again_recommendations(
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    item_id UUID NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    times_bought INTEGER NOT NULL,
+    last_bought_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, item_id)
+);
+
+-- +goose Down
+DROP TABLE buy_again_recommendations;
+DROP TABLE item_recommendations;
+DROP VIEW purchases;
\ No newline at end of file
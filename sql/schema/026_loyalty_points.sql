@@ -0,0 +1,37 @@
+-- +goose Up
+-- loyalty_point_lots are points earned together. Redemptions spend the lots that expire first.
+CREATE TABLE loyalty_point_lots(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    payment_id UUID REFERENCES payments (id) ON DELETE SET NULL,
+    points INTEGER NOT NULL,
+    remaining INTEGER NOT NULL,
+    earned_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    expired_at TIMESTAMP,
+    CHECK (remaining >= 0 AND remaining <= points)
+);
+
+CREATE INDEX loyalty_point_lots_user_id_idx ON loyalty_point_lots (user_id, expires_at);
+
+CREATE TABLE loyalty_point_movements(
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX loyalty_point_movements_user_id_idx ON loyalty_point_movements (user_id, created_at);
+
+CREATE TABLE cart_point_redemptions(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    points INTEGER NOT NULL,
+    CHECK (points > 0)
+);
+
+-- +goose Down
+DROP TABLE cart_point_redemptions;
+DROP TABLE loyalty_point_movements;
+DROP TABLE loyalty_point_lots;
\ No newline at end of file
@@ -0,0 +1,66 @@
+-- +goose Up
+CREATE TABLE gift_cards(
+    id UUID PRIMARY KEY,
+    code TEXT NOT NULL UNIQUE,
+    amount INTEGER NOT NULL,
+    issued_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    expires_at TIMESTAMP,
+    redeemed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    redeemed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (amount > 0)
+);
+
+CREATE TABLE store_credit_accounts(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    balance INTEGER NOT NULL,
+    CHECK (balance >= 0)
+);
+
+-- credit_movements is the accounting ledger of gift cards and store credit. Every row
+-- belongs to either a gift card or a customer's store credit account. The ledger outlives
+-- the users in it: deleting a user only clears the user columns of their rows.
+CREATE TABLE credit_movements(
+    id UUID PRIMARY KEY,
+    gift_card_id UUID REFERENCES gift_cards (id),
+    user_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    delta INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    actor_id UUID REFERENCES users (id) ON DELETE SET NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    CHECK (gift_card_id IS NULL OR user_id IS NULL)
+);
+
+CREATE INDEX credit_movements_user_id_idx ON credit_movements (user_id, created_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION reject_credit_movement_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND NEW.id = OLD.id
+        AND NEW.gift_card_id IS NOT DISTINCT FROM OLD.gift_card_id
+        AND NEW.delta = OLD.delta
+        AND NEW.reason = OLD.reason
+        AND NEW.reference = OLD.reference
+        AND NEW.created_at = OLD.created_at
+        AND (NEW.user_id IS NULL OR NEW.user_id = OLD.user_id)
+        AND (NEW.actor_id IS NULL OR NEW.actor_id = OLD.actor_id)
+    THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'credit_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER credit_movements_append_only
+BEFORE UPDATE OR DELETE ON credit_movements
+FOR EACH ROW EXECUTE FUNCTION reject_credit_movement_change();
+
+-- +goose Down
+DROP TABLE credit_movements;
+DROP FUNCTION reject_credit_movement_change;
+DROP TABLE store_credit_accounts;
+DROP TABLE gift_cards;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE referral_codes(
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    code TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- A referee can be referred only once. The referral stays pending until their first
+-- succeeded payment, then it is rewarded or rejected by the fraud checks.
+CREATE TABLE referrals(
+    id UUID PRIMARY KEY,
+    referrer_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    referee_id UUID NOT NULL UNIQUE REFERENCES users (id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    rejection_reason TEXT NOT NULL DEFAULT '',
+    payment_id UUID REFERENCES payments (id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    CHECK (referrer_id <> referee_id)
+);
+
+CREATE INDEX referrals_referrer_id_idx ON referrals (referrer_id, created_at);
+
+-- +goose Down
+DROP TABLE referrals;
+DROP TABLE referral_codes;
\ No newline at end of file
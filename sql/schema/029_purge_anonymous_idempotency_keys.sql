@@ -0,0 +1,6 @@
+-- +goose Up
+-- Responses stored for unauthenticated requests may hold access and refresh tokens.
+DELETE FROM idempotency_keys WHERE scope = 'anonymous';
+
+-- +goose Down
+-- Irreversible: the purged responses are gone and must not be restored.
\ No newline at end of file